@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// benchOptions is the fixed game every benchmark plays so runs can be
+// compared against each other
+var benchOptions = GameOptions{Rounds: DefaultRounds}
+
+// BenchmarkDecision times the decision of every registered strategy halfway
+// through a game against tit for tat
+func BenchmarkDecision(b *testing.B) {
+	for _, name := range StrategyNames() {
+		b.Run(name, func(b *testing.B) {
+			bot := strategies[name]()
+			game, _, _ := playGame(bot, TitForTatBot{}, GameOptions{Rounds: benchOptions.Rounds / 2}, false)
+			game.Rounds = benchOptions.Rounds
+			state := game.State().Swap()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bot.Decision(state)
+			}
+		})
+	}
+}
+
+// BenchmarkNeuralNetworkBot compares deciding with the network built once,
+// as the bot does now, against parsing the genome again for every move as
+// it used to
+func BenchmarkNeuralNetworkBot(b *testing.B) {
+	game, _, _ := playGame(strategies["NeuralNetworkBot"](), TitForTatBot{}, GameOptions{Rounds: benchOptions.Rounds / 2}, false)
+	game.Rounds = benchOptions.Rounds
+	state := game.State().Swap()
+
+	b.Run("Cached", func(b *testing.B) {
+		bot := strategies["NeuralNetworkBot"]()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			bot.Decision(state)
+		}
+	})
+	b.Run("Reparsed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			net, err := getGenome(championGenome)
+			if err != nil {
+				b.Fatal(err)
+			}
+			NeuralNetworkBot{net: net}.Decision(state)
+		}
+	})
+}
+
+// dispatchBot hides whether the bot it wraps plays a constant move
+type dispatchBot struct {
+	Bot
+}
+
+// BenchmarkGame times a game between two constant bots, and the same game
+// having to ask them for every move
+func BenchmarkGame(b *testing.B) {
+	tests := []struct {
+		name string
+		a, b Bot
+	}{
+		{"ConstantMove", DefectBot{}, CooperateBot{}},
+		{"Decision", dispatchBot{DefectBot{}}, dispatchBot{CooperateBot{}}},
+	}
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				PlayGame(tt.a, tt.b, benchOptions)
+			}
+		})
+	}
+}
+
+func BenchmarkTournament(b *testing.B) {
+	bots := map[string]Bot{}
+	for _, name := range StrategyNames() {
+		bots[name] = strategies[name]()
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := RunTournament(context.Background(), bots, TournamentOptions{GameOptions: benchOptions, Games: 10, Seed: 1})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,20 +1,156 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"github.com/yaricom/goNEAT/v2/neat/genetics"
 	"github.com/yaricom/goNEAT/v2/neat/network"
 	"golang.org/x/exp/rand"
+	"io"
+	"math"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 )
 
 type Bot interface {
 	Decision(state GameState) int
 }
 
-type RandomBot struct{}
+// SeededRand gives a bot its own random source so its moves can be replayed
+// no matter what else draws from the global one. The source is created from
+// Seed the first time it is used, so the zero value is ready to play. A Seed
+// of 0 means unseeded, the source then gets its seed from the global one so
+// unseeded bots don't all draw the same numbers
+type SeededRand struct {
+	Seed uint64
+	rng  *rand.Rand
+}
+
+func (s *SeededRand) Float64() float64 {
+	if s.rng == nil {
+		seed := s.Seed
+		if seed == 0 {
+			seed = rand.Uint64()
+		}
+		s.rng = rand.New(rand.NewSource(seed))
+	}
+	return s.rng.Float64()
+}
+
+// Reseed starts the random source over from seed
+func (s *SeededRand) Reseed(seed uint64) {
+	s.Seed = seed
+	s.rng = nil
+}
+
+// fork gives a copy of the source a stream of its own, one that is still
+// reproducible if the original was seeded
+func (s *SeededRand) fork() {
+	if s.Seed != 0 {
+		s.Seed ^= 0x9e3779b97f4a7c15
+	}
+	s.rng = nil
+}
+
+// Reseeder is implemented by bots with their own random source, like any
+// embedding SeededRand, so tournaments can give them a seed for every game
+type Reseeder interface {
+	Reseed(seed uint64)
+}
+
+func reseedBot(b Bot, seed uint64) {
+	if r, ok := b.(Reseeder); ok {
+		r.Reseed(seed)
+	}
+}
+
+// Resetter is implemented by bots that keep state between rounds, Reset is
+// called before every game so nothing carries over from the last one
+type Resetter interface {
+	Reset()
+}
+
+func resetBot(b Bot) {
+	if r, ok := b.(Resetter); ok {
+		r.Reset()
+	}
+}
+
+// cloneBot makes a second instance of b for when it has to play itself, so
+// the two seats don't share any state. Bots that aren't pointers can't keep
+// state between moves and are returned as they are. Anything else is copied
+// as it is configured, reset, and given a random stream of its own
+func cloneBot(b Bot) Bot {
+	v := reflect.ValueOf(b)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return b
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	clone := c.Interface().(Bot)
+
+	if f, ok := clone.(interface{ fork() }); ok {
+		f.fork()
+	}
+	resetBot(clone)
+	return clone
+}
+
+// Meeter is implemented by bots that want to know who they are playing,
+// tournaments call Meet with the opponent's name before a matchup starts
+type Meeter interface {
+	Meet(opponent string)
+}
+
+func meetBot(b Bot, opponent string) {
+	if m, ok := b.(Meeter); ok {
+		m.Meet(opponent)
+	}
+}
+
+// ConstantMover is implemented by bots that might play the same move every
+// round whatever happens, so games can skip asking them each round
+type ConstantMover interface {
+	ConstantMove() (int, bool)
+}
+
+// constantMove returns the move the bot always plays, if it has one
+func constantMove(b Bot) (int, bool) {
+	if c, ok := b.(ConstantMover); ok {
+		return c.ConstantMove()
+	}
+	return NoMove, false
+}
+
+// Stationary is implemented by deterministic bots that only look back at
+// the last two rounds and not at how many are left, so once the same pair of
+// moves has been played three rounds running they play it again. Only games
+// between two of them can stop early
+type Stationary interface {
+	Stationary() bool
+}
+
+// stationary is true if b says it is, or always plays the same move
+func stationary(b Bot) bool {
+	if s, ok := b.(Stationary); ok && s.Stationary() {
+		return true
+	}
+	_, ok := constantMove(b)
+	return ok
+}
+
+// RandomBot cooperates or defects with even odds
+type RandomBot struct {
+	SeededRand
+}
 
-func (r RandomBot) Decision(state GameState) int {
-	return rand.Intn(2)
+func (r *RandomBot) Decision(state GameState) int {
+	if r.Float64() < 0.5 {
+		return Defect
+	}
+	return Cooperate
 }
 
 type DefectBot struct{}
@@ -23,12 +159,20 @@ func (r DefectBot) Decision(state GameState) int {
 	return Defect
 }
 
+func (r DefectBot) ConstantMove() (int, bool) {
+	return Defect, true
+}
+
 type CooperateBot struct{}
 
 func (r CooperateBot) Decision(state GameState) int {
 	return Cooperate
 }
 
+func (r CooperateBot) ConstantMove() (int, bool) {
+	return Cooperate, true
+}
+
 type TitForTatBot struct{}
 
 func (r TitForTatBot) Decision(state GameState) int {
@@ -38,6 +182,10 @@ func (r TitForTatBot) Decision(state GameState) int {
 	return Cooperate
 }
 
+func (r TitForTatBot) Stationary() bool {
+	return true
+}
+
 type TitForTatBotReverse struct{}
 
 func (r TitForTatBotReverse) Decision(state GameState) int {
@@ -47,33 +195,1343 @@ func (r TitForTatBotReverse) Decision(state GameState) int {
 	return Cooperate
 }
 
-type RandomDefectBot struct{}
+func (r TitForTatBotReverse) Stationary() bool {
+	return true
+}
+
+// MirrorBot copies whatever the opponent played last round without reading
+// anything into it, in the discrete game this is the same as TitForTatBot
+// but any other move is copied as it is too. It cooperates in the first
+// round
+type MirrorBot struct{}
+
+func (r MirrorBot) Decision(state GameState) int {
+	if state.aPrevious == NoMove {
+		return Cooperate
+	}
+	return state.aPrevious
+}
+
+func (r MirrorBot) Stationary() bool {
+	return true
+}
+
+// SoftReset lets a grudge bot let go of old defections. Every After rounds
+// in a row the opponent cooperates, the oldest defection it still holds
+// against the opponent is forgiven, whatever the bot itself was playing so
+// a bot that is still retaliating can forgive. A defection starts the run
+// again. It never forgives if After is unset
+type SoftReset struct {
+	After int
+}
+
+// grudge adds up what weight says each of the opponent's defections so far
+// is worth, leaving out the ones that have been forgiven
+func (s SoftReset) grudge(state GameState, weight func(round int) float64) float64 {
+	g := grudgeTracker{SoftReset: s}
+	for i := range state.aHistory {
+		g.observe(state.aHistory[i], weight(i))
+	}
+
+	grudge := 0.0
+	for _, w := range g.held {
+		grudge += w
+	}
+	return grudge
+}
+
+// grudgeTracker keeps the opponent's defections that haven't been forgiven
+// yet as the game is played through round by round
+type grudgeTracker struct {
+	SoftReset
+	held []float64
+	run  int // rounds the opponent has cooperated in a row
+}
+
+func (g *grudgeTracker) observe(opponent int, weight float64) {
+	switch opponent {
+	case Defect:
+		g.held = append(g.held, weight)
+		g.run = 0
+	case Cooperate:
+		g.run++
+		if g.After > 0 && g.run%g.After == 0 && len(g.held) > 0 {
+			g.held = g.held[1:]
+		}
+	default:
+		g.run = 0
+	}
+}
+
+// ThresholdGrimBot cooperates until the opponent has defected K times in
+// total and then defects for the rest of the game, K of 1 is Grim Trigger.
+// With SoftReset the defections it forgives stop counting towards K
+type ThresholdGrimBot struct {
+	K int
+	SoftReset
+}
+
+func (r ThresholdGrimBot) Decision(state GameState) int {
+	defections := r.grudge(state, func(int) float64 { return 1 })
+	if defections >= float64(r.K) {
+		return Defect
+	}
+	return Cooperate
+}
+
+// GrimBot is Grim Trigger, it cooperates until the opponent defects and
+// then defects for the rest of the game. With SoftReset it goes back to
+// cooperating once the opponent has cooperated After rounds in a row
+type GrimBot struct {
+	SoftReset
+}
+
+func (r GrimBot) Decision(state GameState) int {
+	return ThresholdGrimBot{K: 1, SoftReset: r.SoftReset}.Decision(state)
+}
+
+// GradualBot cooperates until the opponent defects, and answers its n-th
+// defection by defecting n times and then cooperating twice to calm things
+// down, whatever the opponent does meanwhile. With SoftReset n only counts
+// the defections it hasn't forgiven
+type GradualBot struct {
+	SoftReset
+}
+
+func (r GradualBot) Decision(state GameState) int {
+	g := grudgeTracker{SoftReset: r.SoftReset}
+	var plan []int
+	for _, move := range state.aHistory {
+		g.observe(move, 1)
+		if len(plan) > 0 {
+			plan = plan[1:]
+			continue
+		}
+		if move == Defect {
+			for range g.held {
+				plan = append(plan, Defect)
+			}
+			plan = append(plan, Cooperate, Cooperate)
+		}
+	}
+	if len(plan) > 0 {
+		return plan[0]
+	}
+	return Cooperate
+}
+
+// ForgivingGrudgerBot cooperates until the opponent defects, then defects
+// for Punish rounds whatever the opponent does before forgiving it
+// completely and cooperating again. A Punish as long as the game is Grim
+// Trigger
+type ForgivingGrudgerBot struct {
+	Punish int
+}
+
+func (r ForgivingGrudgerBot) Decision(state GameState) int {
+	punishing := 0
+	for _, move := range state.aHistory {
+		switch {
+		case punishing > 0:
+			punishing--
+		case move == Defect:
+			punishing = r.Punish
+		}
+	}
+	if punishing > 0 {
+		return Defect
+	}
+	return Cooperate
+}
+
+// SeverityGrimBot holds a grudge that grows with every betrayal by how much
+// it cost, measured against the reward for cooperating. Being suckered
+// costs more than a mutual defection so it counts for more. Once the grudge
+// reaches Threshold, 6 as a named strategy, it defects for the rest of the
+// game, or with SoftReset until it has forgiven enough of them
+type SeverityGrimBot struct {
+	Threshold float64
+	SoftReset
+}
+
+func (r SeverityGrimBot) Decision(state GameState) int {
+	if r.Grudge(state) >= r.Threshold {
+		return Defect
+	}
+	return Cooperate
+}
+
+// Grudge is what the bot has lost to the opponent's defections so far that
+// it hasn't forgiven
+func (r SeverityGrimBot) Grudge(state GameState) float64 {
+	payoff := state.bPayoff
+	return r.grudge(state, func(i int) float64 {
+		own, _ := payoff.Score(state.bHistory[i], state.aHistory[i])
+		return float64(payoff.Reward - own)
+	})
+}
+
+// PenanceBot plays tit for tat but every time it defects, whether it meant
+// to or not, it follows up with Penance rounds of cooperation whatever the
+// opponent does to show it wants to get back to cooperating
+type PenanceBot struct {
+	Penance int
+}
+
+func (r PenanceBot) Decision(state GameState) int {
+	penance := 0
+	for _, own := range state.bHistory {
+		switch {
+		case own == Defect:
+			penance = r.Penance
+		case penance > 0:
+			penance--
+		}
+	}
+	if penance > 0 {
+		return Cooperate
+	}
+	return TitForTatBot{}.Decision(state)
+}
+
+// ContriteTitForTatBot plays tit for tat but keeps track of who is in good
+// standing. A player is in good standing after cooperating, or after
+// defecting against an opponent that wasn't, so it only defects when it is in
+// good standing and the opponent isn't. After defecting by mistake it takes
+// its punishment without answering, so two of them get back to cooperating
+// two rounds later instead of echoing the defection back and forth
+type ContriteTitForTatBot struct{}
+
+func (r ContriteTitForTatBot) Decision(state GameState) int {
+	own, opponent := true, true
+	for i := range state.bHistory {
+		own, opponent = state.bHistory[i] == Cooperate || !opponent,
+			state.aHistory[i] == Cooperate || !own
+	}
+	if own && !opponent {
+		return Defect
+	}
+	return Cooperate
+}
+
+// FixedMixedBot draws once at the start of each game whether it will
+// cooperate for the whole game, with probability CoopProb, or defect for the
+// whole game. Over many games it stands in for a population of pure
+// cooperators and defectors rather than one bot mixing every round
+type FixedMixedBot struct {
+	CoopProb float64
+	SeededRand
+	move  int
+	drawn bool
+}
+
+func (r *FixedMixedBot) Decision(state GameState) int {
+	// drawn on the first move rather than in Reset so it comes from the
+	// game's seed whichever order a tournament resets and reseeds in
+	if !r.drawn {
+		r.move = Defect
+		if r.Float64() < r.CoopProb {
+			r.move = Cooperate
+		}
+		r.drawn = true
+	}
+	return r.move
+}
+
+func (r *FixedMixedBot) Reset() {
+	r.drawn = false
+}
+
+// BayesianBot keeps a Beta belief about how likely the opponent is to
+// cooperate after each of its own moves, starting from a Beta(Alpha, Beta)
+// prior (Beta(1, 1) if unset). It plays whichever move scores best this
+// round plus the next, since what it plays now changes how the opponent is
+// likely to answer
+type BayesianBot struct {
+	Alpha float64
+	Beta  float64
+}
+
+func (r BayesianBot) Decision(state GameState) int {
+	if len(state.bHistory) == 0 {
+		return Cooperate
+	}
+
+	afterCooperate, afterDefect := r.Posterior(state)
+	now := afterCooperate
+	if state.bHistory[len(state.bHistory)-1] == Defect {
+		now = afterDefect
+	}
+
+	p := state.bPayoff
+	cooperate := expectedScore(p, Cooperate, now) + bestExpectedScore(p, afterCooperate)
+	defect := expectedScore(p, Defect, now) + bestExpectedScore(p, afterDefect)
+	if cooperate > defect {
+		return Cooperate
+	}
+	return Defect
+}
+
+// Posterior returns the posterior mean chance the opponent cooperates in
+// the round after the bot cooperated and after it defected
+func (r BayesianBot) Posterior(state GameState) (float64, float64) {
+	alpha, beta := r.Alpha, r.Beta
+	if alpha <= 0 || beta <= 0 {
+		alpha, beta = 1, 1
+	}
+
+	var coop, seen [2]float64
+	for i := 1; i < len(state.aHistory); i++ {
+		own := state.bHistory[i-1]
+		if own != Cooperate && own != Defect {
+			continue
+		}
+		seen[own]++
+		if state.aHistory[i] == Cooperate {
+			coop[own]++
+		}
+	}
+
+	return (alpha + coop[Cooperate]) / (alpha + beta + seen[Cooperate]),
+		(alpha + coop[Defect]) / (alpha + beta + seen[Defect])
+}
+
+// expectedScore is what playing move is worth against an opponent who
+// cooperates with probability coop
+func expectedScore(p Payoff, move int, coop float64) float64 {
+	ifCooperate, _ := p.Score(move, Cooperate)
+	ifDefect, _ := p.Score(move, Defect)
+	return coop*float64(ifCooperate) + (1-coop)*float64(ifDefect)
+}
+
+func bestExpectedScore(p Payoff, coop float64) float64 {
+	return math.Max(expectedScore(p, Cooperate, coop), expectedScore(p, Defect, coop))
+}
+
+// FortressBot opens with two defections as a recognition handshake. If the
+// opponent answered with two defections as well it cooperates from the
+// third round for as long as the opponent keeps cooperating, anyone else
+// gets defected against for the rest of the game
+type FortressBot struct{}
+
+func (r FortressBot) Decision(state GameState) int {
+	if len(state.aHistory) < 2 {
+		return Defect
+	}
+	if state.aHistory[0] != Defect || state.aHistory[1] != Defect {
+		return Defect
+	}
+	if countMoves(state.aHistory[2:], Defect) > 0 {
+		return Defect
+	}
+	return Cooperate
+}
+
+// MemoryNBot looks its move up in Table keyed by the last N rounds, oldest
+// first, with each round written as the bot's own move then the opponent's
+// as C or D. So with N of 2 the key "CCCD" means both cooperated two rounds
+// ago and the opponent defected last round. Early in the game the key only
+// covers the rounds played so far ("" on the first round) and any key
+// missing from the table cooperates
+type MemoryNBot struct {
+	N     int
+	Table map[string]int
+}
+
+func (r MemoryNBot) Decision(state GameState) int {
+	if move, ok := r.Table[historyKey(state, r.N)]; ok {
+		return move
+	}
+	return Cooperate
+}
+
+// historyKey is the MemoryNBot key for the last n rounds of state
+func historyKey(state GameState, n int) string {
+	start := len(state.bHistory) - n
+	if start < 0 {
+		start = 0
+	}
+
+	var sb strings.Builder
+	for i := start; i < len(state.bHistory); i++ {
+		sb.WriteByte(moveLetter(state.bHistory[i]))
+		sb.WriteByte(moveLetter(state.aHistory[i]))
+	}
+	return sb.String()
+}
+
+func moveLetter(move int) byte {
+	if move == Defect {
+		return 'D'
+	}
+	return 'C'
+}
+
+// BackwardInductionBot plays tit for tat until the last K rounds of the
+// game, then defects since there is no future round left to be punished in
+type BackwardInductionBot struct {
+	K int
+}
+
+func (r BackwardInductionBot) Decision(state GameState) int {
+	if state.RoundsLeft() <= r.K {
+		return Defect
+	}
+	return TitForTatBot{}.Decision(state)
+}
+
+// SteinAndRapoportBot cooperates for four rounds and then plays tit for tat.
+// Every fifteen rounds it runs a chi-squared test on the opponent's moves
+// and if they look like coin flips it defects for the rest of the game, it
+// also defects on the last two rounds
+type SteinAndRapoportBot struct{}
 
-func (r RandomDefectBot) Decision(state GameState) int {
-	if rand.Intn(10) == 0 {
+func (r SteinAndRapoportBot) Decision(state GameState) int {
+	round := len(state.bHistory) + 1
+	if round < 5 {
+		return Cooperate
+	}
+	if state.RoundsLeft() <= 2 {
 		return Defect
 	}
+
+	for check := 15; check <= round; check += 15 {
+		if looksRandom(state.aHistory[:check-1]) {
+			return Defect
+		}
+	}
+
+	return state.aPrevious
+}
+
+// looksRandom is a chi-squared test at the 5% level of whether moves could
+// have come from a fair coin
+func looksRandom(moves []int) bool {
+	expected := float64(len(moves)) / 2
+	if expected == 0 {
+		return false
+	}
+
+	chiSquared := 0.0
+	for _, move := range []int{Cooperate, Defect} {
+		diff := float64(countMoves(moves, move)) - expected
+		chiSquared += diff * diff / expected
+	}
+
+	// critical value for one degree of freedom
+	return chiSquared <= 3.841
+}
+
+// GrofmanBot cooperates for the first two rounds, after that it cooperates
+// whenever both players made the same move last round and only two times in
+// seven when they didn't
+type GrofmanBot struct {
+	SeededRand
+}
+
+func (r *GrofmanBot) Decision(state GameState) int {
+	if len(state.bHistory) < 2 || state.aPrevious == state.bPrevious {
+		return Cooperate
+	}
+	if r.Float64() < 2.0/7.0 {
+		return Cooperate
+	}
+	return Defect
+}
+
+// ShubikBot cooperates until the opponent defects against its cooperation,
+// then retaliates with a run of defections that is one longer each time it
+// is provoked before going back to cooperating
+type ShubikBot struct {
+	provocations int
+	retaliations int // defections left in the current run
+}
+
+func (r *ShubikBot) Decision(state GameState) int {
+	if r.retaliations > 0 {
+		r.retaliations--
+		return Defect
+	}
+
+	if len(state.bHistory) > 0 && state.aPrevious == Defect && state.bPrevious == Cooperate {
+		r.provocations++
+		r.retaliations = r.provocations - 1
+		return Defect
+	}
+
 	return Cooperate
 }
 
-type OftenRandomDefectBot struct{}
+func (r *ShubikBot) Reset() {
+	r.provocations = 0
+	r.retaliations = 0
+}
+
+// RegretMatchingBot treats cooperating and defecting as two experts. It
+// adds up how much better each would have done than what it actually played
+// against the opponent's real moves, and picks each one in proportion to
+// that regret when it is positive, or at random when neither is
+type RegretMatchingBot struct {
+	SeededRand
+}
+
+func (r *RegretMatchingBot) Decision(state GameState) int {
+	cooperate, defect := r.Regret(state)
+	cooperate = math.Max(cooperate, 0)
+	defect = math.Max(defect, 0)
 
-func (r OftenRandomDefectBot) Decision(state GameState) int {
-	if rand.Intn(3) == 0 {
+	if cooperate+defect == 0 {
+		if r.Float64() < 0.5 {
+			return Cooperate
+		}
+		return Defect
+	}
+	if r.Float64() < cooperate/(cooperate+defect) {
+		return Cooperate
+	}
+	return Defect
+}
+
+// Regret returns the total regret for not having always cooperated and for
+// not having always defected
+func (r *RegretMatchingBot) Regret(state GameState) (float64, float64) {
+	var cooperate, defect float64
+	for i, opponent := range state.aHistory {
+		actual, _ := state.bPayoff.Score(state.bHistory[i], opponent)
+		ifCooperate, _ := state.bPayoff.Score(Cooperate, opponent)
+		ifDefect, _ := state.bPayoff.Score(Defect, opponent)
+		cooperate += float64(ifCooperate - actual)
+		defect += float64(ifDefect - actual)
+	}
+	return cooperate, defect
+}
+
+// RemorsefulProberBot plays tit for tat but now and then defects out of the
+// blue with probability Probe to see what it can get away with. When the
+// opponent hits back straight after one of those probes it accepts it was
+// at fault and cooperates instead of retaliating
+type RemorsefulProberBot struct {
+	Probe float64
+	SeededRand
+}
+
+func (r *RemorsefulProberBot) Decision(state GameState) int {
+	n := len(state.bHistory)
+	if n == 0 {
+		return Cooperate
+	}
+
+	if state.aPrevious == Defect {
+		if n >= 2 && isProbe(state, n-2) {
+			return Cooperate
+		}
+		return Defect
+	}
+
+	if r.Float64() < r.Probe {
+		return Defect
+	}
+	return Cooperate
+}
+
+// NaiveProberBot plays tit for tat but whenever it would cooperate it
+// defects instead with probability Probe, and unlike RemorsefulProberBot it
+// never makes up for it
+type NaiveProberBot struct {
+	Probe float64
+	SeededRand
+}
+
+func (r *NaiveProberBot) Decision(state GameState) int {
+	if len(state.aHistory) == 0 {
+		return Cooperate
+	}
+	if state.aPrevious == Defect || r.Float64() < r.Probe {
 		return Defect
 	}
 	return Cooperate
 }
 
+// ProberFamilyBot opens with the moves in Probe and then plays tit for tat,
+// unless how the opponent answered the probe gives it away. The answers are
+// the opponent's moves from the second round to the end of the probe. With
+// ExploitIfLenient it defects for the rest of the game if none of them were
+// defections, and with TrustIfReciprocal it cooperates for the rest of the
+// game if they were exactly what tit for tat would have answered
+type ProberFamilyBot struct {
+	Probe             []int
+	ExploitIfLenient  bool
+	TrustIfReciprocal bool
+}
+
+// NewProberBot opens D, C, C and exploits an opponent that cooperated in
+// the second and third rounds
+func NewProberBot() ProberFamilyBot {
+	return ProberFamilyBot{Probe: []int{Defect, Cooperate, Cooperate}, ExploitIfLenient: true}
+}
+
+// NewProber2Bot opens D, C, C and cooperates for good with an opponent that
+// answered D, C in the second and third rounds
+func NewProber2Bot() ProberFamilyBot {
+	return ProberFamilyBot{Probe: []int{Defect, Cooperate, Cooperate}, TrustIfReciprocal: true}
+}
+
+// NewProber3Bot opens D, C and exploits an opponent that cooperated in the
+// second round
+func NewProber3Bot() ProberFamilyBot {
+	return ProberFamilyBot{Probe: []int{Defect, Cooperate}, ExploitIfLenient: true}
+}
+
+func (r ProberFamilyBot) Decision(state GameState) int {
+	round := len(state.bHistory)
+	if round < len(r.Probe) {
+		return r.Probe[round]
+	}
+
+	if len(r.Probe) > 0 {
+		answers := state.aHistory[1:len(r.Probe)]
+		if r.ExploitIfLenient && countMoves(answers, Defect) == 0 {
+			return Defect
+		}
+		if r.TrustIfReciprocal && reciprocated(answers, r.Probe) {
+			return Cooperate
+		}
+	}
+	return TitForTatBot{}.Decision(state)
+}
+
+// reciprocated is true if every answer copied the probe move before it
+func reciprocated(answers, probe []int) bool {
+	for i, answer := range answers {
+		if answer != probe[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isProbe is true if the bot's move in round i was a defection the opponent
+// had done nothing to deserve
+func isProbe(state GameState, i int) bool {
+	return state.bHistory[i] == Defect && (i == 0 || state.aHistory[i-1] != Defect)
+}
+
+// FirmButFairBot cooperates after mutual cooperation, after being suckered
+// and after getting away with a defection, but after mutual defection it
+// only cooperates with probability Forgive (so never by default)
+type FirmButFairBot struct {
+	Forgive float64
+	SeededRand
+}
+
+func (r *FirmButFairBot) Decision(state GameState) int {
+	if len(state.bHistory) == 0 {
+		return Cooperate
+	}
+	if state.aPrevious == Defect && state.bPrevious == Defect {
+		if r.Forgive > 0 && r.Float64() < r.Forgive {
+			return Cooperate
+		}
+		return Defect
+	}
+	return Cooperate
+}
+
+// SlowTitForTatBot cooperates for the first two rounds, after that it only
+// changes its mind once the opponent has played the same move two rounds
+// running and otherwise keeps playing whatever it played last
+type SlowTitForTatBot struct{}
+
+func (r SlowTitForTatBot) Decision(state GameState) int {
+	n := len(state.aHistory)
+	if n < 2 {
+		return Cooperate
+	}
+	if state.aHistory[n-1] == state.aHistory[n-2] {
+		return state.aHistory[n-1]
+	}
+	return state.bPrevious
+}
+
+func (r SlowTitForTatBot) Stationary() bool {
+	return true
+}
+
+// HardTitForTatBot defects if the opponent defected in either of the last
+// two rounds, so it punishes for longer than TitForTatBot
+type HardTitForTatBot struct{}
+
+func (r HardTitForTatBot) Decision(state GameState) int {
+	recent := state.aHistory
+	if len(recent) > 2 {
+		recent = recent[len(recent)-2:]
+	}
+	if countMoves(recent, Defect) > 0 {
+		return Defect
+	}
+	return Cooperate
+}
+
+func (r HardTitForTatBot) Stationary() bool {
+	return true
+}
+
+// LastKMajorityBot cooperates if the opponent cooperated more than it
+// defected over the last K rounds, K of 0 counts the whole game. A tie, which
+// includes the first round, cooperates if TieCooperate is set
+type LastKMajorityBot struct {
+	K            int
+	TieCooperate bool
+}
+
+func (r LastKMajorityBot) Decision(state GameState) int {
+	window := state.aHistory
+	if r.K > 0 && len(window) > r.K {
+		window = window[len(window)-r.K:]
+	}
+
+	cooperations := countMoves(window, Cooperate)
+	defections := countMoves(window, Defect)
+	if cooperations > defections || (cooperations == defections && r.TieCooperate) {
+		return Cooperate
+	}
+	return Defect
+}
+
+// EatherleyBot cooperates unless the opponent defected last round, and
+// even then it only retaliates with the probability of a defection given
+// how often the opponent has defected so far
+type EatherleyBot struct {
+	SeededRand
+}
+
+func (r *EatherleyBot) Decision(state GameState) int {
+	if len(state.aHistory) == 0 || state.aPrevious != Defect {
+		return Cooperate
+	}
+	if r.Float64() < defectionRate(state.aHistory) {
+		return Defect
+	}
+	return Cooperate
+}
+
+// defectionRate is the fraction of moves that were defections
+func defectionRate(moves []int) float64 {
+	if len(moves) == 0 {
+		return 0
+	}
+	return float64(countMoves(moves, Defect)) / float64(len(moves))
+}
+
+// ChampionBot plays in three phases. It cooperates through the first 5% of
+// the game and plays tit for tat until 12.5% of the way through, which in
+// the 200 round games of Axelrod's second tournament were rounds 10 and 25.
+// After that it cooperates unless the opponent defected last round and has
+// defected in more than Threshold of all rounds, 40% as a named strategy
+type ChampionBot struct {
+	Threshold float64
+}
+
+func (r ChampionBot) Decision(state GameState) int {
+	round := float64(len(state.aHistory))
+	rounds := float64(state.rounds)
+	if round < 0.05*rounds {
+		return Cooperate
+	}
+	if round < 0.125*rounds {
+		return TitForTatBot{}.Decision(state)
+	}
+
+	if state.aPrevious == Defect && defectionRate(state.aHistory) > r.Threshold {
+		return Defect
+	}
+	return Cooperate
+}
+
+// TidemanChieruzziBot plays tit for tat but every time the opponent starts
+// a new run of defections it retaliates for one round longer. When it is at
+// least 10 points ahead, at least 20 rounds have passed since the last
+// fresh start, there are more than 10 rounds to go and the opponent looks
+// like it is playing at random it offers a fresh start, cooperating twice
+// and forgetting about every previous run of defections
+type TidemanChieruzziBot struct {
+	runs         int // runs of defections the opponent has started
+	retaliations int // defections left in the current retaliation
+	freshStart   int // cooperations left in the current fresh start
+	lastFresh    int // round of the last fresh start
+}
+
+func (r *TidemanChieruzziBot) Decision(state GameState) int {
+	n := len(state.aHistory)
+	if n == 0 {
+		return Cooperate
+	}
+	if r.freshStart > 0 {
+		r.freshStart--
+		return Cooperate
+	}
+
+	newRun := state.aPrevious == Defect && (n == 1 || state.aHistory[n-2] == Cooperate)
+	if !newRun && r.offerFreshStart(state) {
+		r.runs = 0
+		r.retaliations = 0
+		r.freshStart = 1
+		r.lastFresh = n
+		return Cooperate
+	}
+
+	if newRun {
+		r.runs++
+		r.retaliations = r.runs
+	}
+	if r.retaliations > 0 {
+		r.retaliations--
+		return Defect
+	}
+	return TitForTatBot{}.Decision(state)
+}
+
+func (r *TidemanChieruzziBot) offerFreshStart(state GameState) bool {
+	own, opponent := scores(state)
+	return own-opponent >= 10 &&
+		len(state.aHistory)-r.lastFresh >= 20 &&
+		state.RoundsLeft() > 10 &&
+		looksRandom(state.aHistory)
+}
+
+func (r *TidemanChieruzziBot) Reset() {
+	*r = TidemanChieruzziBot{}
+}
+
+// scores totals up the bot's and its opponent's score so far
+func scores(state GameState) (int, int) {
+	own, opponent := 0, 0
+	for i := range state.aHistory {
+		b, _ := state.bPayoff.Score(state.bHistory[i], state.aHistory[i])
+		_, a := state.aPayoff.Score(state.bHistory[i], state.aHistory[i])
+		own += b
+		opponent += a
+	}
+	return own, opponent
+}
+
+// GraaskampBot plays tit for tat for 50 rounds and defects on round 51 to
+// see how the opponent reacts. It goes back to tit for tat afterwards but
+// from round 57, if the opponent looks like it is playing at random or is
+// just copying its moves, it also defects every fifth round since neither
+// is going to hold a grudge
+type GraaskampBot struct{}
+
+func (r GraaskampBot) Decision(state GameState) int {
+	n := len(state.aHistory)
+	if n == 50 {
+		return Defect
+	}
+	if n >= 56 && n%5 == 0 && (looksRandom(state.aHistory) || copiesMoves(state)) {
+		return Defect
+	}
+	return TitForTatBot{}.Decision(state)
+}
+
+// copiesMoves is true if every move the opponent has made after the first
+// was the bot's move from the round before, as tit for tat would
+func copiesMoves(state GameState) bool {
+	for i := 1; i < len(state.aHistory); i++ {
+		if state.aHistory[i] != state.bHistory[i-1] {
+			return false
+		}
+	}
+	return len(state.aHistory) > 1
+}
+
+// NydeggerBot plays tit for tat for the first three rounds, except it
+// defects on the third if it was the only one cooperating on the first and
+// the only one defecting on the second. After that it looks up the last
+// three rounds with nydeggerIndex and defects if the index is one of
+// nydeggerDefections
+type NydeggerBot struct{}
+
+// nydeggerDefections are the last three rounds after which Nydegger's
+// original design defects
+var nydeggerDefections = map[int]bool{
+	1: true, 6: true, 7: true, 17: true, 22: true, 23: true, 26: true,
+	29: true, 30: true, 31: true, 33: true, 38: true, 39: true, 45: true,
+	49: true, 54: true, 55: true, 58: true, 61: true,
+}
+
+func (r NydeggerBot) Decision(state GameState) int {
+	n := len(state.aHistory)
+	if n < 3 {
+		if n == 2 &&
+			state.bHistory[0] == Cooperate && state.aHistory[0] == Defect &&
+			state.bHistory[1] == Defect && state.aHistory[1] == Cooperate {
+			return Defect
+		}
+		return TitForTatBot{}.Decision(state)
+	}
+
+	if nydeggerDefections[nydeggerIndex(state)] {
+		return Defect
+	}
+	return Cooperate
+}
+
+// nydeggerIndex scores each of the last three rounds 0 if both cooperated,
+// 2 if only the opponent defected, 1 if only the bot did and 3 if both did,
+// then weights the last round by 16, the one before by 4 and the one before
+// that by 1
+func nydeggerIndex(state GameState) int {
+	index := 0
+	n := len(state.aHistory)
+	for i, weight := range []int{16, 4, 1} {
+		own, opponent := state.bHistory[n-1-i], state.aHistory[n-1-i]
+		index += weight * (2*opponent + own)
+	}
+	return index
+}
+
+// TullockBot cooperates for the first eleven rounds, after that it
+// cooperates with the probability the opponent cooperated over the last ten
+// rounds less Margin, so it always trusts a little less than it has been
+// trusted. As a named strategy the margin is 10%
+type TullockBot struct {
+	Margin float64
+	SeededRand
+}
+
+func (r *TullockBot) Decision(state GameState) int {
+	if len(state.aHistory) < 11 {
+		return Cooperate
+	}
+	if r.Float64() < r.Cooperation(state) {
+		return Cooperate
+	}
+	return Defect
+}
+
+// Cooperation is how likely the bot is to cooperate this round once the
+// first eleven are over
+func (r *TullockBot) Cooperation(state GameState) float64 {
+	recent := state.aHistory
+	if len(recent) > 10 {
+		recent = recent[len(recent)-10:]
+	}
+	return math.Max(1-defectionRate(recent)-r.Margin, 0)
+}
+
+// FictitiousPlayBot assumes the opponent cooperates with a fixed
+// probability that depends on what the bot itself played the round before,
+// estimated from how often it has done so far. It then plays whichever of
+// always cooperating or always defecting would score best per round against
+// that opponent
+type FictitiousPlayBot struct{}
+
+func (r FictitiousPlayBot) Decision(state GameState) int {
+	if len(state.aHistory) == 0 {
+		return Cooperate
+	}
+
+	afterCooperate, afterDefect := r.Frequencies(state)
+	if expectedScore(state.bPayoff, Cooperate, afterCooperate) >= expectedScore(state.bPayoff, Defect, afterDefect) {
+		return Cooperate
+	}
+	return Defect
+}
+
+// Frequencies returns how often the opponent has cooperated after the bot
+// cooperated and after it defected, each starting from a half so one round
+// doesn't settle it
+func (r FictitiousPlayBot) Frequencies(state GameState) (float64, float64) {
+	var cooperations, rounds [2]int
+	for i := 1; i < len(state.aHistory); i++ {
+		own := state.bHistory[i-1]
+		rounds[own]++
+		if state.aHistory[i] == Cooperate {
+			cooperations[own]++
+		}
+	}
+	frequency := func(move int) float64 {
+		return (float64(cooperations[move]) + 1) / (float64(rounds[move]) + 2)
+	}
+	return frequency(Cooperate), frequency(Defect)
+}
+
+// AdaptiveGenerousTFTBot plays tit for tat but forgives a defection with a
+// probability it tunes as it goes. It starts at Initial and every time it
+// finds itself in a second mutual defection in a row it forgives more by
+// Step, while every time it is suckered twice in a row it forgives less by
+// the same amount. As a named strategy it starts at 10% with steps of 5%
+type AdaptiveGenerousTFTBot struct {
+	Initial float64
+	Step    float64
+	SeededRand
+}
+
+func (r *AdaptiveGenerousTFTBot) Decision(state GameState) int {
+	if state.aPrevious != Defect {
+		return Cooperate
+	}
+	if r.Float64() < r.Forgiveness(state) {
+		return Cooperate
+	}
+	return Defect
+}
+
+// Forgiveness is the probability of forgiving a defection this round given
+// how the game has gone so far
+func (r *AdaptiveGenerousTFTBot) Forgiveness(state GameState) float64 {
+	forgiveness := r.Initial
+	for i := 1; i < len(state.aHistory); i++ {
+		own, opponent := state.bHistory[i], state.aHistory[i]
+		ownBefore, opponentBefore := state.bHistory[i-1], state.aHistory[i-1]
+		switch {
+		case own == Defect && opponent == Defect && ownBefore == Defect && opponentBefore == Defect:
+			forgiveness = math.Min(forgiveness+r.Step, 1)
+		case own == Cooperate && opponent == Defect && ownBefore == Cooperate && opponentBefore == Defect:
+			forgiveness = math.Max(forgiveness-r.Step, 0)
+		}
+	}
+	return forgiveness
+}
+
+// AdaptiveBot cooperates for six rounds and defects for the next six, then
+// keeps playing whichever move has scored it more per round so far
+type AdaptiveBot struct{}
+
+var adaptiveOpening = []int{
+	Cooperate, Cooperate, Cooperate, Cooperate, Cooperate, Cooperate,
+	Defect, Defect, Defect, Defect, Defect, Defect,
+}
+
+func (r AdaptiveBot) Decision(state GameState) int {
+	n := len(state.aHistory)
+	if n < len(adaptiveOpening) {
+		return adaptiveOpening[n]
+	}
+
+	cooperate, defect := r.Averages(state)
+	if cooperate > defect {
+		return Cooperate
+	}
+	return Defect
+}
+
+// Averages is the bot's mean score in the rounds it cooperated and in the
+// rounds it defected
+func (r AdaptiveBot) Averages(state GameState) (float64, float64) {
+	var total [2]int
+	var count [2]int
+	for i := range state.aHistory {
+		own, _ := state.bPayoff.Score(state.bHistory[i], state.aHistory[i])
+		total[state.bHistory[i]] += own
+		count[state.bHistory[i]]++
+	}
+
+	var average [2]float64
+	for move := range average {
+		if count[move] > 0 {
+			average[move] = float64(total[move]) / float64(count[move])
+		}
+	}
+	return average[Cooperate], average[Defect]
+}
+
+// ReactiveBot cooperates with probability P1 after the opponent cooperated
+// and P2 after it defected, and cooperates on the first round. P1 of 1 and
+// P2 of 0 is tit for tat
+type ReactiveBot struct {
+	P1 float64
+	P2 float64
+	SeededRand
+}
+
+func (r *ReactiveBot) Decision(state GameState) int {
+	if len(state.aHistory) == 0 {
+		return Cooperate
+	}
+	p := r.P1
+	if state.aPrevious == Defect {
+		p = r.P2
+	}
+	if r.Float64() < p {
+		return Cooperate
+	}
+	return Defect
+}
+
+// Scoreboard is how well each of a set of strategies is doing, shared
+// between bots so they can learn from each other. It is safe to use from
+// several goroutines at once
+type Scoreboard struct {
+	mu         sync.RWMutex
+	strategies map[string]func() Bot
+	scores     map[string]float64
+}
+
+// NewScoreboard starts every strategy on zero, an ImitatorBot copying one of
+// them builds its own bot to play with the strategy's constructor
+func NewScoreboard(strategies map[string]func() Bot) *Scoreboard {
+	scores := make(map[string]float64, len(strategies))
+	for name := range strategies {
+		scores[name] = 0
+	}
+	return &Scoreboard{strategies: strategies, scores: scores}
+}
+
+// Set records the latest score of a strategy on the board
+func (s *Scoreboard) Set(name string, score float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.strategies[name]; ok {
+		s.scores[name] = score
+	}
+}
+
+// Update sets the score of every strategy on the board that played in the
+// tournament to its mean score per game
+func (s *Scoreboard) Update(result TournamentResult) {
+	for _, name := range result.Bots() {
+		standing := result.Standing(name)
+		if standing.Games > 0 {
+			s.Set(name, float64(standing.Score)/float64(standing.Games))
+		}
+	}
+}
+
+// Leader is the strategy with the highest score, the first by name on a
+// tie, and empty if the board is
+func (s *Scoreboard) Leader() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	leader, found := "", false
+	for name, score := range s.scores {
+		best := s.scores[leader]
+		if !found || score > best || (score == best && name < leader) {
+			leader, found = name, true
+		}
+	}
+	return leader
+}
+
+// newBot builds a bot playing the named strategy, nil if it isn't on the
+// board
+func (s *Scoreboard) newBot(name string) Bot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if newBot, ok := s.strategies[name]; ok {
+		return newBot()
+	}
+	return nil
+}
+
+// ImitatorBot plays whatever the strategy leading the scoreboard would play,
+// switching as soon as the leader changes. It plays a copy of each strategy
+// of its own, so imitators sharing a board don't share any state, and
+// cooperates if the board is empty
+type ImitatorBot struct {
+	Scoreboard *Scoreboard
+	copies     map[string]Bot
+	seed       uint64
+}
+
+func (r *ImitatorBot) Decision(state GameState) int {
+	if r.Scoreboard == nil {
+		return Cooperate
+	}
+	name := r.Scoreboard.Leader()
+	leader, ok := r.copies[name]
+	if !ok {
+		leader = r.Scoreboard.newBot(name)
+		if leader == nil {
+			return Cooperate
+		}
+		if r.seed != 0 {
+			reseedBot(leader, r.seed)
+		}
+		if r.copies == nil {
+			r.copies = map[string]Bot{}
+		}
+		r.copies[name] = leader
+	}
+	return leader.Decision(state)
+}
+
+// Reset throws away the copies so every game starts with new ones
+func (r *ImitatorBot) Reset() {
+	r.copies = nil
+}
+
+// Reseed is passed on to the copies, including ones built later in the game
+func (r *ImitatorBot) Reseed(seed uint64) {
+	r.seed = seed
+	for _, b := range r.copies {
+		reseedBot(b, seed)
+	}
+}
+
+// Reputations are how often each strategy has been seen to cooperate,
+// shared between bots so they can judge an opponent by how it treated
+// everyone else. It is safe to use from several goroutines at once
+type Reputations struct {
+	mu    sync.RWMutex
+	rates map[string]float64
+}
+
+// NewReputations starts with nobody having a reputation
+func NewReputations() *Reputations {
+	return &Reputations{rates: map[string]float64{}}
+}
+
+// Set records how often a strategy has cooperated, from 0 to 1
+func (r *Reputations) Set(name string, rate float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rates[name] = rate
+}
+
+// Update sets the reputation of every strategy that played in the
+// tournament to how often it cooperated
+func (r *Reputations) Update(result TournamentResult) {
+	for _, name := range result.Bots() {
+		r.Set(name, result.CooperationRate(name))
+	}
+}
+
+// Rate is how often the strategy has cooperated, and false if it has no
+// reputation yet
+func (r *Reputations) Rate(name string) (float64, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rate, ok := r.rates[name]
+	return rate, ok
+}
+
+// ReputationBot cooperates with opponents whose reputation is at least
+// Threshold and defects against the rest, however they play against it.
+// It gives opponents it doesn't know, or hasn't been told the name of, the
+// benefit of the doubt
+type ReputationBot struct {
+	Reputations *Reputations
+	Threshold   float64
+	opponent    string
+}
+
+func (r *ReputationBot) Meet(opponent string) {
+	r.opponent = opponent
+}
+
+func (r *ReputationBot) Decision(state GameState) int {
+	if r.Reputations == nil {
+		return Cooperate
+	}
+	rate, ok := r.Reputations.Rate(r.opponent)
+	if ok && rate < r.Threshold {
+		return Defect
+	}
+	return Cooperate
+}
+
+// RandomDefectBot cooperates but defects at random with a chance of Rate
+// each round. As a named strategy it defects 1 in 10 rounds
+type RandomDefectBot struct {
+	Rate float64
+	SeededRand
+}
+
+func (r *RandomDefectBot) Decision(state GameState) int {
+	if r.Float64() < r.Rate {
+		return Defect
+	}
+	return Cooperate
+}
+
+// OftenRandomDefectBot is RandomDefectBot under a name of its own, as a
+// named strategy it defects 1 in 3 rounds
+type OftenRandomDefectBot struct {
+	Rate float64
+	SeededRand
+}
+
+func (r *OftenRandomDefectBot) Decision(state GameState) int {
+	if r.Float64() < r.Rate {
+		return Defect
+	}
+	return Cooperate
+}
+
+// ScriptedBot plays Moves in order, once they run out it starts again from
+// the top if Loop is set, otherwise it keeps playing the last move
+type ScriptedBot struct {
+	Moves []int
+	Loop  bool
+	next  int
+}
+
+func (r *ScriptedBot) Decision(state GameState) int {
+	if len(r.Moves) == 0 {
+		return Cooperate
+	}
+
+	i := r.next
+	if i >= len(r.Moves) {
+		if r.Loop {
+			i %= len(r.Moves)
+		} else {
+			i = len(r.Moves) - 1
+		}
+	}
+	r.next++
+
+	return r.Moves[i]
+}
+
+func (r *ScriptedBot) Reset() {
+	r.next = 0
+}
+
 type NeuralNetworkBot struct {
 	net *network.Network
+	// Sensors are the inputs the network was trained with, if unset it is
+	// worked out from how many inputs the network has, which only tells
+	// DefaultSensors and DefaultSensors with SenseScoreDifference apart
+	Sensors SensorSet
+}
+
+// NewNeuralNetworkBotFromReader builds the bot from a genome in the format
+// goNEAT writes, like the best file saved during training
+func NewNeuralNetworkBotFromReader(r io.Reader) (*NeuralNetworkBot, error) {
+	genome, err := genetics.ReadGenome(r, 1)
+	if err != nil {
+		return nil, fmt.Errorf("reading genome: %w", err)
+	}
+	return NewNeuralNetworkBot(genome)
+}
+
+// NewNeuralNetworkBot builds the bot from the network genome describes
+func NewNeuralNetworkBot(genome *genetics.Genome) (*NeuralNetworkBot, error) {
+	// goNEAT reads a gene naming a node that isn't there as a nil node and
+	// then panics building the network from it
+	for _, gene := range genome.Genes {
+		if gene.Link == nil || gene.Link.InNode == nil || gene.Link.OutNode == nil {
+			return nil, fmt.Errorf("genome %d has gene %d linking a node it doesn't have", genome.Id, gene.InnovationNum)
+		}
+	}
+	net, err := genome.Genesis(1)
+	if err != nil {
+		return nil, fmt.Errorf("building network: %w", err)
+	}
+	if len(net.Outputs) != 1 {
+		return nil, fmt.Errorf("genome %d has %d outputs, the bot needs 1", genome.Id, len(net.Outputs))
+	}
+	return &NeuralNetworkBot{net: net}, nil
 }
 
 func (r NeuralNetworkBot) Decision(state GameState) int {
-	_ = r.net.LoadSensors([]float64{
-		float64(state.aPrevious),
-		float64(state.bPrevious),
-	})
+	sensors := r.Sensors
+	if sensors == 0 {
+		sensors = guessSensors(r.net)
+	}
+	_ = r.net.LoadSensors(sensors.Values(state))
 
 	_, _ = r.net.Activate()
 	outputs := r.net.ReadOutputs()
@@ -87,10 +1545,167 @@ func (r NeuralNetworkBot) Decision(state GameState) int {
 	return decision
 }
 
-func getGenome(genomeStr string) *network.Network {
-	genome, _ := genetics.ReadGenome(strings.NewReader(genomeStr), 1)
+// Reset flushes the network so nothing it took in during the last game is
+// still circulating when the next one starts
+func (r NeuralNetworkBot) Reset() {
+	_, _ = r.net.Flush()
+}
+
+// ExtractMemoryOneTable probes the network with each of the four outcomes
+// of the previous round and returns how likely it is to cooperate next, in
+// the order CC, CD, DC, DD with the network's own move first. The network is
+// given the inputs in sensors, the set it was trained with, and any that
+// aren't about the last round are held at a neutral value: halfway through
+// the game with the scores level. Sets that look further back than the last
+// round can't be summed up by the table and return an error
+func ExtractMemoryOneTable(net *network.Network, sensors SensorSet) ([4]float64, error) {
+	var table [4]float64
+	sensors = sensors.orDefault()
+	if sensors&SensePreviousMoves == 0 {
+		return table, errors.New("a memory one table needs the network to sense the previous moves")
+	}
+	if sensors&SenseHistory != 0 {
+		return table, errors.New("SenseHistory looks back further than a memory one table can hold")
+	}
+
+	for i, outcome := range []Outcome{
+		{A: Cooperate, B: Cooperate},
+		{A: Cooperate, B: Defect},
+		{A: Defect, B: Cooperate},
+		{A: Defect, B: Defect},
+	} {
+		// flushing clears what working out the depth marks as visited, so it
+		// has to come first
+		if _, err := net.Flush(); err != nil {
+			return table, err
+		}
+		// activate as deep as the network goes so every hidden node has fed
+		// through to the output, the same as during training
+		netDepth, err := net.MaxActivationDepthFast(0)
+		if err != nil || netDepth == 0 {
+			netDepth = 1
+		}
+
+		// the network sits in seat B of the state it is shown
+		state := GameState{aPrevious: outcome.B, bPrevious: outcome.A, round: 1, rounds: 2}
+		if err := net.LoadSensors(sensors.Values(state)); err != nil {
+			return table, err
+		}
+		if _, err := net.ForwardSteps(netDepth); err != nil {
+			return table, err
+		}
+
+		// anything over 0.5 is played as a defection
+		table[i] = 1 - net.ReadOutputs()[0]
+	}
+
+	return table, nil
+}
 
-	net, _ := genome.Genesis(1)
+// championGenome is the best network found so far by the NEAT training
+const championGenome = `/* Organism #0 Fitness: 33.000 Error: 0.000 */
+genomestart 0
+trait 1 0 0 0 0 0 0 0 0
+node 1 1 1 1 SigmoidSteepenedActivation
+node 2 1 1 3 SigmoidSteepenedActivation
+node 3 1 0 0 SigmoidSteepenedActivation
+node 13 1 0 2 SigmoidSteepenedActivation
+gene 1 2 3 0.47155578767902206 false 27 0.47155578767902206 true
+gene 1 2 13 -0.024576662955294593 false 157 -0.024576662955294593 true
+gene 1 3 13 1.4502147215405494 false 158 1.4502147215405494 true
+genomeend 0
+`
 
-	return net
+func getGenome(genomeStr string) (*network.Network, error) {
+	bot, err := NewNeuralNetworkBotFromReader(strings.NewReader(genomeStr))
+	if err != nil {
+		return nil, err
+	}
+	return bot.net, nil
+}
+
+// championBot plays championGenome, which is known to be good so not being
+// able to build it is a bug
+func championBot() NeuralNetworkBot {
+	net, err := getGenome(championGenome)
+	if err != nil {
+		panic("champion genome: " + err.Error())
+	}
+	return NeuralNetworkBot{net: net}
+}
+
+func countMoves(history []int, move int) int {
+	count := 0
+	for _, m := range history {
+		if m == move {
+			count++
+		}
+	}
+	return count
+}
+
+// strategies holds a constructor for every named bot so each match can
+// start from a fresh bot with nothing left over from the last one
+var strategies = map[string]func() Bot{
+	"RandomBot":              func() Bot { return &RandomBot{} },
+	"TitForTatBot":           func() Bot { return TitForTatBot{} },
+	"DefectBot":              func() Bot { return DefectBot{} },
+	"CooperateBot":           func() Bot { return CooperateBot{} },
+	"RandomDefectBot":        func() Bot { return &RandomDefectBot{Rate: 1.0 / 10} },
+	"TitForTatBotReverse":    func() Bot { return TitForTatBotReverse{} },
+	"ContriteTitForTatBot":   func() Bot { return ContriteTitForTatBot{} },
+	"OftenRandomDefectBot":   func() Bot { return &OftenRandomDefectBot{Rate: 1.0 / 3} },
+	"MirrorBot":              func() Bot { return MirrorBot{} },
+	"GrimBot":                func() Bot { return GrimBot{} },
+	"GradualBot":             func() Bot { return GradualBot{} },
+	"ThresholdGrimBot":       func() Bot { return ThresholdGrimBot{K: 3} },
+	"BayesianBot":            func() Bot { return BayesianBot{} },
+	"FortressBot":            func() Bot { return FortressBot{} },
+	"BackwardInductionBot":   func() Bot { return BackwardInductionBot{K: 2} },
+	"SteinAndRapoportBot":    func() Bot { return SteinAndRapoportBot{} },
+	"GrofmanBot":             func() Bot { return &GrofmanBot{} },
+	"ShubikBot":              func() Bot { return &ShubikBot{} },
+	"RegretMatchingBot":      func() Bot { return &RegretMatchingBot{} },
+	"RemorsefulProberBot":    func() Bot { return &RemorsefulProberBot{Probe: 0.1} },
+	"FirmButFairBot":         func() Bot { return &FirmButFairBot{} },
+	"SlowTitForTatBot":       func() Bot { return SlowTitForTatBot{} },
+	"HardTitForTatBot":       func() Bot { return HardTitForTatBot{} },
+	"LastKMajorityBot":       func() Bot { return LastKMajorityBot{K: 5, TieCooperate: true} },
+	"EatherleyBot":           func() Bot { return &EatherleyBot{} },
+	"ChampionBot":            func() Bot { return ChampionBot{Threshold: 0.4} },
+	"TidemanChieruzziBot":    func() Bot { return &TidemanChieruzziBot{} },
+	"GraaskampBot":           func() Bot { return GraaskampBot{} },
+	"NydeggerBot":            func() Bot { return NydeggerBot{} },
+	"TullockBot":             func() Bot { return &TullockBot{Margin: 0.1} },
+	"NaiveProberBot":         func() Bot { return &NaiveProberBot{Probe: 0.1} },
+	"ProberBot":              func() Bot { return NewProberBot() },
+	"Prober2Bot":             func() Bot { return NewProber2Bot() },
+	"Prober3Bot":             func() Bot { return NewProber3Bot() },
+	"FictitiousPlayBot":      func() Bot { return FictitiousPlayBot{} },
+	"AdaptiveGenerousTFTBot": func() Bot { return &AdaptiveGenerousTFTBot{Initial: 0.1, Step: 0.05} },
+	"AdaptiveBot":            func() Bot { return AdaptiveBot{} },
+	"ForgivingGrudgerBot":    func() Bot { return ForgivingGrudgerBot{Punish: 4} },
+	"SeverityGrimBot":        func() Bot { return SeverityGrimBot{Threshold: 6} },
+	"PenanceBot":             func() Bot { return PenanceBot{Penance: 2} },
+	"FixedMixedBot":          func() Bot { return &FixedMixedBot{CoopProb: 0.5} },
+	"NeuralNetworkBot":       func() Bot { return championBot() },
+}
+
+// NewBot returns a fresh bot playing the named strategy
+func NewBot(name string) (Bot, error) {
+	create, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q, expected one of: %s", name, strings.Join(StrategyNames(), ", "))
+	}
+	return create(), nil
+}
+
+// StrategyNames returns the names of all the registered strategies in order
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
@@ -0,0 +1,1478 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// scripted plays moves written as a string of C and D
+func scripted(moves string) *ScriptedBot {
+	bot := &ScriptedBot{}
+	for _, c := range moves {
+		move := Cooperate
+		if c == 'D' {
+			move = Defect
+		}
+		bot.Moves = append(bot.Moves, move)
+	}
+	return bot
+}
+
+func TestImitatorBotFollowsLeader(t *testing.T) {
+	strategies := map[string]func() Bot{
+		"CooperateBot": func() Bot { return CooperateBot{} },
+		"DefectBot":    func() Bot { return DefectBot{} },
+	}
+	board := NewScoreboard(strategies)
+	bots := map[string]Bot{}
+	for name, newBot := range strategies {
+		bots[name] = newBot()
+	}
+	result, err := RunTournament(context.Background(), bots, TournamentOptions{Games: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// always defecting beats always cooperating
+	board.Update(result)
+
+	bot := &ImitatorBot{Scoreboard: board}
+	steps := []struct {
+		name   string
+		leader string
+		score  float64
+		want   int
+	}{
+		{"defector leads after the tournament", "", 0, Defect},
+		{"cooperator overtakes", "CooperateBot", 100, Cooperate},
+		{"defector takes it back", "DefectBot", 200, Defect},
+	}
+	opponent, own := "", ""
+	for _, step := range steps {
+		if step.leader != "" {
+			board.Set(step.leader, step.score)
+		}
+		got := bot.Decision(historyState(opponent, own))
+		if got != step.want {
+			t.Errorf("%s: played %c, want %c", step.name, moveLetter(got), moveLetter(step.want))
+		}
+		opponent, own = opponent+"C", own+string(moveLetter(got))
+	}
+}
+
+func TestScriptedBot(t *testing.T) {
+	tests := []struct {
+		name string
+		bot  *ScriptedBot
+		want string
+	}{
+		{"holds the last move", scripted("CDDC"), "CDDCCCCCCCC"},
+		{"holds a defection", scripted("CCD"), "CCDDDDDDDDD"},
+		{"loops", &ScriptedBot{Moves: scripted("CDD").Moves, Loop: true}, "CDDCDDCDDCD"},
+		{"empty", &ScriptedBot{}, "CCCCCCCCCCC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := scripted(tt.want).Moves
+			// playing twice checks Reset starts the script again
+			for i := 0; i < 2; i++ {
+				resetBot(tt.bot)
+				got := make([]int, len(want))
+				for j := range got {
+					got[j] = tt.bot.Decision(GameState{})
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("game %d played %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMirrorBot(t *testing.T) {
+	tests := []struct {
+		opponent string
+		want     string
+	}{
+		{"CCCCC", "CCCCC"},
+		{"DDDDD", "CDDDD"},
+		{"CDCDC", "CCDCD"},
+		{"DDCCD", "CDDCC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.opponent, func(t *testing.T) {
+			want := scripted(tt.want).Moves
+			// the first round has no previous move
+			previous := -1
+			for i, move := range scripted(tt.opponent).Moves {
+				state := GameState{aPrevious: previous}
+				got := MirrorBot{}.Decision(state)
+				if got != want[i] {
+					t.Errorf("round %d played %d, want %d", i, got, want[i])
+				}
+				// in the discrete game it is tit for tat
+				if tft := (TitForTatBot{}).Decision(state); tft != got {
+					t.Errorf("round %d tit for tat played %d, mirror played %d", i, tft, got)
+				}
+				previous = move
+			}
+		})
+	}
+
+	// there is no continuous game yet, but a move that is neither
+	// cooperating nor defecting still comes back unchanged
+	for _, move := range []int{2, 5, 100} {
+		if got := (MirrorBot{}).Decision(GameState{aPrevious: move}); got != move {
+			t.Errorf("opponent played %d, mirror played %d", move, got)
+		}
+	}
+}
+
+func TestNeuralNetworkBotInputs(t *testing.T) {
+	// only the first input is wired to the output, so the network repeats
+	// whatever that input says it played last round
+	net, err := getGenome(`genomestart 1
+trait 1 0 0 0 0 0 0 0 0
+node 1 1 1 1 SigmoidSteepenedActivation
+node 2 1 1 3 SigmoidSteepenedActivation
+node 3 1 0 2 SigmoidSteepenedActivation
+gene 1 1 3 10 false 1 10 true
+genomeend 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := NeuralNetworkBot{net: net}
+
+	tests := []struct {
+		name     string
+		state    GameState
+		expected int
+	}{
+		{"own defection", GameState{aPrevious: Cooperate, bPrevious: Defect}, Defect},
+		{"own cooperation", GameState{aPrevious: Defect, bPrevious: Cooperate}, Cooperate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bot.Decision(tt.state); got != tt.expected {
+				t.Errorf("played %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNeuralNetworkBotReset(t *testing.T) {
+	// the output feeds back into itself strongly enough that once the
+	// opponent defects the network keeps defecting, like GrimBot, for as
+	// long as nothing flushes it
+	net, err := getGenome(`genomestart 1
+trait 1 0 0 0 0 0 0 0 0
+node 1 1 1 1 SigmoidSteepenedActivation
+node 2 1 1 1 SigmoidSteepenedActivation
+node 3 1 0 2 SigmoidSteepenedActivation
+node 4 1 1 3 SigmoidSteepenedActivation
+gene 1 2 3 10 false 1 10 true
+gene 1 3 3 20 true 2 20 true
+gene 1 4 3 -5 false 3 -5 true
+genomeend 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := NeuralNetworkBot{net: net}
+
+	opponent := &ScriptedBot{Moves: moves("DC")}
+	for i := 0; i < 3; i++ {
+		if got, want := movesString(PlayGame(bot, opponent, GameOptions{}).AHistory), "CDDDDDDDDD"; got != want {
+			t.Errorf("game %d played %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestThresholdGrimBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		k        int
+		opponent string
+		want     string
+	}{
+		// two scattered defections aren't enough, the third triggers it
+		{"K 3", 3, "CDCCDCCCDCCC", "CCCCCCCCCDDD"},
+		{"K 3 in a row", 3, "DDDCCC", "CCCDDD"},
+		{"K 3 only twice", 3, "DCCCCCCD", "CCCCCCCC"},
+		{"K 1 is Grim Trigger", 1, "CCDCCC", "CCCDDD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := ThresholdGrimBot{K: tt.k}
+			// a second game checks nothing carries over from the first
+			for i := 0; i < 2; i++ {
+				game := playRounds(scripted(tt.opponent), bot, len(tt.opponent))
+				if want := scripted(tt.want).Moves; !reflect.DeepEqual(game.BHistory, want) {
+					t.Errorf("game %d played %v, want %v", i, game.BHistory, want)
+				}
+			}
+		})
+	}
+}
+
+// moves reads a string of C and D
+func moves(s string) []int {
+	return scripted(s).Moves
+}
+
+// historyState is what a bot sees once the opponent has played opponent and
+// it has played own
+func historyState(opponent, own string) GameState {
+	state := GameState{aHistory: moves(opponent), bHistory: moves(own)}
+	if n := len(state.aHistory); n > 0 {
+		state.aPrevious, state.bPrevious = state.aHistory[n-1], state.bHistory[n-1]
+	}
+	return state
+}
+
+func TestBayesianBotPosterior(t *testing.T) {
+	tests := []struct {
+		name                        string
+		opponent, own               string
+		afterCooperate, afterDefect float64
+	}{
+		{"prior", "", "", 0.5, 0.5},
+		{"cooperation after cooperating", "CCCC", "CCCC", 0.8, 0.5},
+		{"answers in kind", "CDCD", "DCDC", 2.0 / 3, 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := GameState{aHistory: moves(tt.opponent), bHistory: moves(tt.own), bPayoff: DefaultPayoff}
+			c, d := BayesianBot{}.Posterior(state)
+			if math.Abs(c-tt.afterCooperate) > 1e-9 || math.Abs(d-tt.afterDefect) > 1e-9 {
+				t.Errorf("Posterior = %.3f, %.3f, want %.3f, %.3f", c, d, tt.afterCooperate, tt.afterDefect)
+			}
+		})
+	}
+}
+
+func TestBayesianBotLearnsToCooperate(t *testing.T) {
+	game := playRounds(TitForTatBot{}, BayesianBot{}, 100)
+	if countMoves(game.BHistory[50:], Defect) > 0 {
+		t.Errorf("still defecting against tit for tat: %v", game.BHistory[50:])
+	}
+	// tit for tat always answers cooperation with cooperation
+	if c, _ := (BayesianBot{}).Posterior(game.State()); c < 0.95 {
+		t.Errorf("posterior after cooperating is %.3f, want it close to 1", c)
+	}
+}
+
+func TestFortressBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent Bot
+		want     string
+	}{
+		// two fortresses recognise each other and cooperate from then on
+		{"FortressBot", FortressBot{}, "DDCCCCCCCCC"},
+		{"CooperateBot", CooperateBot{}, "DDDDDDDDDDD"},
+		{"TitForTatBot", TitForTatBot{}, "DDDDDDDDDDD"},
+		{"half a handshake", scripted("DCCCCCCCCCC"), "DDDDDDDDDDD"},
+		{"defects after the handshake", scripted("DDCCCDCCCCC"), "DDCCCCDDDDD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := playRounds(tt.opponent, FortressBot{}, len(tt.want))
+			if want := moves(tt.want); !reflect.DeepEqual(game.BHistory, want) {
+				t.Errorf("played %v against %v, want %v", game.BHistory, game.AHistory, want)
+			}
+		})
+	}
+}
+
+func TestMemoryNBot(t *testing.T) {
+	// keyed on the opponent's last move, the second letter, this is tit
+	// for tat
+	tft := MemoryNBot{N: 1, Table: map[string]int{"CD": Defect, "DD": Defect}}
+	for _, opponent := range []string{"CCCCCCCCCCC", "DDDDDDDDDDD", "CDCDCDCDCDC", "DDCCDCCCDDC"} {
+		t.Run(opponent, func(t *testing.T) {
+			want := playRounds(scripted(opponent), TitForTatBot{}, len(opponent))
+			got := playRounds(scripted(opponent), tft, len(opponent))
+			if !reflect.DeepEqual(got.BHistory, want.BHistory) {
+				t.Errorf("played %v, tit for tat played %v", got.BHistory, want.BHistory)
+			}
+		})
+	}
+}
+
+func TestHistoryKey(t *testing.T) {
+	tests := []struct {
+		opponent, own string
+		n             int
+		want          string
+	}{
+		{"", "", 2, ""},
+		{"D", "C", 2, "CD"},
+		{"DC", "CC", 2, "CDCC"},
+		{"CCDC", "DDCC", 2, "CDCC"},
+		{"CCDC", "DDCC", 1, "CC"},
+	}
+	for _, tt := range tests {
+		state := GameState{aHistory: moves(tt.opponent), bHistory: moves(tt.own)}
+		if got := historyKey(state, tt.n); got != tt.want {
+			t.Errorf("historyKey(%s against %s, %d) = %q, want %q", tt.own, tt.opponent, tt.n, got, tt.want)
+		}
+	}
+}
+
+// memoryOneGenome is a network with an input for each player's last move,
+// a bias and a single output, wired with the given weights from the bot's
+// own move, the opponent's and the bias
+func memoryOneGenome(own, opponent, bias float64) string {
+	return fmt.Sprintf(`genomestart 1
+trait 1 0 0 0 0 0 0 0 0
+node 1 1 1 1 SigmoidSteepenedActivation
+node 2 1 1 1 SigmoidSteepenedActivation
+node 3 1 1 3 SigmoidSteepenedActivation
+node 4 1 0 2 SigmoidSteepenedActivation
+gene 1 1 4 %[1]v false 1 %[1]v true
+gene 1 2 4 %[2]v false 2 %[2]v true
+gene 1 3 4 %[3]v false 3 %[3]v true
+genomeend 1`, own, opponent, bias)
+}
+
+func TestExtractMemoryOneTable(t *testing.T) {
+	// a network that also senses how far through the game it is, defecting
+	// late in the game and playing tit for tat halfway through
+	timed := `genomestart 1
+trait 1 0 0 0 0 0 0 0 0
+node 1 1 1 1 SigmoidSteepenedActivation
+node 2 1 1 1 SigmoidSteepenedActivation
+node 3 1 1 1 SigmoidSteepenedActivation
+node 4 1 1 3 SigmoidSteepenedActivation
+node 5 1 0 2 SigmoidSteepenedActivation
+gene 1 2 5 20 false 1 20 true
+gene 1 3 5 20 false 2 20 true
+gene 1 4 5 -15 false 3 -15 true
+genomeend 1`
+	tests := []struct {
+		name    string
+		genome  string
+		sensors SensorSet
+		want    [4]float64 // chance of cooperating after CC, CD, DC, DD
+	}{
+		{"ALLD", memoryOneGenome(0, 0, 10), DefaultSensors, [4]float64{0, 0, 0, 0}},
+		{"ALLC", memoryOneGenome(0, 0, -10), DefaultSensors, [4]float64{1, 1, 1, 1}},
+		{"TFT", memoryOneGenome(0, 20, -10), DefaultSensors, [4]float64{1, 0, 1, 0}},
+		{"round held halfway", timed, DefaultSensors | SenseRound, [4]float64{1, 0, 1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, err := getGenome(tt.genome)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ExtractMemoryOneTable(net, tt.sensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 0.01 {
+					t.Fatalf("ExtractMemoryOneTable = %.3f, want %v", got, tt.want)
+				}
+			}
+			// the same again, whatever working out the table left behind
+			if again, err := ExtractMemoryOneTable(net, tt.sensors); err != nil || again != got {
+				t.Errorf("second table %.3f, %v, want %.3f", again, err, got)
+			}
+		})
+	}
+
+	net, err := getGenome(memoryOneGenome(0, 20, -10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sensors := range []SensorSet{SenseRound, SensePreviousMoves | SenseHistory} {
+		if _, err := ExtractMemoryOneTable(net, sensors); err == nil {
+			t.Errorf("sensors %b gave a memory one table", sensors)
+		}
+	}
+}
+
+func TestBackwardInductionBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		k        int
+		opponent Bot
+		rounds   int
+		want     string
+	}{
+		// cooperates through round 18 and defects in rounds 19 and 20
+		{"K 2 against a cooperator", 2, CooperateBot{}, 20, "CCCCCCCCCCCCCCCCCCDD"},
+		{"K 2 default game", 2, CooperateBot{}, 0, "CCCCCCCCDD"},
+		{"K 0 is tit for tat", 0, CooperateBot{}, 20, "CCCCCCCCCCCCCCCCCCCC"},
+		{"K 3 against a defector", 3, DefectBot{}, 6, "CDDDDD"},
+		{"K longer than the game", 30, CooperateBot{}, 5, "DDDDD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(tt.opponent, BackwardInductionBot{K: tt.k}, GameOptions{Rounds: tt.rounds})
+			if got := movesString(game.BHistory); got != tt.want {
+				t.Errorf("played %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSteinAndRapoportBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent Bot
+		check    func(moves string) bool
+	}{
+		// tit for tat after the first four rounds, except the last two
+		{"TitForTatBot", TitForTatBot{}, func(moves string) bool {
+			return moves == strings.Repeat("C", 98)+"DD"
+		}},
+		{"DefectBot", DefectBot{}, func(moves string) bool {
+			return moves == "CCCC"+strings.Repeat("D", 96)
+		}},
+		// an even mix looks like coin flips to the first test, after fifteen
+		// rounds
+		{"even mix", &ScriptedBot{Moves: moves("CDDCCDDC"), Loop: true}, func(moves string) bool {
+			return strings.Trim(moves[14:], "D") == ""
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(tt.opponent, SteinAndRapoportBot{}, GameOptions{Rounds: 100})
+			if got := movesString(game.BHistory); !tt.check(got) {
+				t.Errorf("played %s against %s", got, movesString(game.AHistory))
+			}
+		})
+	}
+}
+
+func TestGrofmanBot(t *testing.T) {
+	tests := []struct {
+		name          string
+		opponent, own string
+		rate          float64
+	}{
+		{"first round", "", "", 1},
+		{"second round", "D", "C", 1},
+		{"both cooperated", "CC", "CC", 1},
+		{"both defected", "CD", "CD", 1},
+		{"suckered", "CD", "CC", 2.0 / 7},
+		{"got away with it", "CC", "CD", 2.0 / 7},
+	}
+	const samples = 20000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, tt.own)
+			bot := &GrofmanBot{SeededRand{Seed: 1}}
+			cooperated := 0
+			for i := 0; i < samples; i++ {
+				if bot.Decision(state) == Cooperate {
+					cooperated++
+				}
+			}
+			// well over four standard deviations for a rate of 2/7
+			if got := float64(cooperated) / samples; math.Abs(got-tt.rate) > 0.015 {
+				t.Errorf("cooperated %.3f of the time, want %.3f", got, tt.rate)
+			}
+		})
+	}
+}
+
+func TestShubikBot(t *testing.T) {
+	tests := []struct {
+		name          string
+		opponent, own string
+	}{
+		{"never provoked", "CCCCCC", "CCCCCC"},
+		{"escalates", "DCCCDCCCCCDCCCCC", "CDCCCDDCCCCDDDCC"},
+		// defecting back during a retaliation doesn't count as a provocation
+		{"answered retaliation", "DDCCCC", "CDCCCC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(scripted(tt.opponent), &ShubikBot{}, GameOptions{Rounds: len(tt.opponent)})
+			if got := movesString(game.BHistory); got != tt.own {
+				t.Errorf("played %s, want %s", got, tt.own)
+			}
+		})
+	}
+}
+
+func TestRegretMatchingBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent Bot
+	}{
+		{"CooperateBot", CooperateBot{}},
+		{"DefectBot", DefectBot{}},
+		{"even mix", &ScriptedBot{Moves: moves("CDDCCDDC"), Loop: true}},
+	}
+	for _, tt := range tests {
+		for seed := uint64(1); seed <= 5; seed++ {
+			t.Run(fmt.Sprintf("%s/seed %d", tt.name, seed), func(t *testing.T) {
+				bot := &RegretMatchingBot{SeededRand{Seed: seed}}
+				game := PlayGame(tt.opponent, bot, GameOptions{Rounds: 200})
+				// defecting is the best response to any fixed mix of moves
+				if got := countMoves(game.BHistory[100:], Defect); got < 95 {
+					t.Errorf("defected %d of the last 100 rounds", got)
+				}
+			})
+		}
+	}
+}
+
+func TestRemorsefulProberBot(t *testing.T) {
+	tests := []struct {
+		name          string
+		opponent, own string
+		want          int
+	}{
+		{"retaliation for a probe", "CCCD", "CCDC", Cooperate},
+		{"unprovoked defection", "CCCD", "CCCC", Defect},
+		{"retaliation for a retaliation", "CDD", "CCD", Defect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, tt.own)
+			bot := &RemorsefulProberBot{Probe: 0}
+			if got := bot.Decision(state); got != tt.want {
+				t.Errorf("got %c, want %c", moveLetter(got), moveLetter(tt.want))
+			}
+		})
+	}
+
+	// against tit for tat every defection it sees was set off by a probe, so
+	// it should never hit back
+	for seed := uint64(1); seed <= 5; seed++ {
+		bot := &RemorsefulProberBot{Probe: 0.2, SeededRand: SeededRand{Seed: seed}}
+		game := PlayGame(TitForTatBot{}, bot, GameOptions{Rounds: 100})
+		for i := 1; i < len(game.BHistory); i++ {
+			if game.AHistory[i-1] == Defect && game.BHistory[i] == Defect {
+				t.Errorf("seed %d: retaliated on round %d of %s against %s", seed, i+1,
+					movesString(game.BHistory), movesString(game.AHistory))
+				break
+			}
+		}
+	}
+}
+
+func TestFirmButFairBot(t *testing.T) {
+	tests := []struct {
+		name          string
+		opponent, own string
+		forgive       float64
+		want          int
+	}{
+		{"first round", "", "", 0, Cooperate},
+		{"mutual cooperation", "C", "C", 0, Cooperate},
+		{"suckered", "D", "C", 0, Cooperate},
+		{"got away with it", "C", "D", 0, Cooperate},
+		{"mutual defection", "D", "D", 0, Defect},
+		{"mutual defection, forgiving", "D", "D", 1, Cooperate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, tt.own)
+			bot := &FirmButFairBot{Forgive: tt.forgive, SeededRand: SeededRand{Seed: 1}}
+			if got := bot.Decision(state); got != tt.want {
+				t.Errorf("got %c, want %c", moveLetter(got), moveLetter(tt.want))
+			}
+		})
+	}
+}
+
+func TestSlowTitForTatBot(t *testing.T) {
+	tests := []struct {
+		name          string
+		opponent, own string
+		want          int
+	}{
+		{"first round", "", "", Cooperate},
+		{"second round", "D", "C", Cooperate},
+		{"cooperated twice", "DCC", "CDC", Cooperate},
+		{"cooperated twice after defecting", "DCC", "CDD", Cooperate},
+		{"defected twice", "CDD", "CCC", Defect},
+		{"defected twice after defecting", "CDD", "CCD", Defect},
+		{"just defected, holds cooperation", "CCD", "CCC", Cooperate},
+		{"just defected, holds defection", "DDCD", "CCDD", Defect},
+		{"just cooperated, holds cooperation", "CDC", "CCC", Cooperate},
+		{"just cooperated, holds defection", "DDC", "CCD", Defect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, tt.own)
+			if got := (SlowTitForTatBot{}).Decision(state); got != tt.want {
+				t.Errorf("got %c, want %c", moveLetter(got), moveLetter(tt.want))
+			}
+		})
+	}
+}
+
+func TestHardTitForTatBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent string
+		want     int
+	}{
+		{"first round", "", Cooperate},
+		{"just defected", "CCD", Defect},
+		{"defected two rounds ago", "CDC", Defect},
+		{"defected three rounds ago", "DCC", Cooperate},
+		{"defected on the first round", "DC", Defect},
+		{"cooperating", "CCC", Cooperate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, strings.Repeat("C", len(tt.opponent)))
+			if got := (HardTitForTatBot{}).Decision(state); got != tt.want {
+				t.Errorf("got %c, want %c", moveLetter(got), moveLetter(tt.want))
+			}
+		})
+	}
+}
+
+func TestLastKMajorityBot(t *testing.T) {
+	tests := []struct {
+		name         string
+		opponent     string
+		tieCooperate bool
+		want         int
+	}{
+		{"first round", "", false, Defect},
+		{"first round, ties cooperate", "", true, Cooperate},
+		{"mostly cooperated", "DCC", false, Cooperate},
+		{"older defections forgotten", "DDDDCCD", false, Cooperate},
+		{"older cooperation forgotten", "CCCCDDC", false, Defect},
+		{"tie in a short history", "CD", false, Defect},
+		{"tie in a short history, ties cooperate", "CD", true, Cooperate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, strings.Repeat("C", len(tt.opponent)))
+			bot := LastKMajorityBot{K: 3, TieCooperate: tt.tieCooperate}
+			if got := bot.Decision(state); got != tt.want {
+				t.Errorf("got %c, want %c", moveLetter(got), moveLetter(tt.want))
+			}
+		})
+	}
+}
+
+func TestEatherleyBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent string
+		rate     float64 // chance of retaliating
+	}{
+		{"first round", "", 0},
+		{"just cooperated", "DDDC", 0},
+		{"always defected", "DD", 1},
+		{"defected half the time", "CDCD", 0.5},
+		{"defected a quarter of the time", "CCCD", 0.25},
+		{"defected an eighth of the time", "CCCCCCCD", 0.125},
+	}
+	const samples = 20000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, strings.Repeat("C", len(tt.opponent)))
+			bot := &EatherleyBot{SeededRand{Seed: 1}}
+			defected := 0
+			for i := 0; i < samples; i++ {
+				if bot.Decision(state) == Defect {
+					defected++
+				}
+			}
+			if got := float64(defected) / samples; math.Abs(got-tt.rate) > 0.015 {
+				t.Errorf("retaliated %.3f of the time, want %.3f", got, tt.rate)
+			}
+		})
+	}
+}
+
+func TestChampionBot(t *testing.T) {
+	// an opponent history of n rounds with d defections, the last round
+	// being last
+	history := func(n, d int, last string) string {
+		if last == "D" {
+			d--
+		}
+		return strings.Repeat("D", d) + strings.Repeat("C", n-d-1) + last
+	}
+	tests := []struct {
+		name      string
+		opponent  string
+		rounds    int
+		threshold float64
+		want      int
+	}{
+		{"end of cooperating", history(9, 1, "D"), 200, 0.4, Cooperate},
+		{"start of tit for tat", history(10, 1, "D"), 200, 0.4, Defect},
+		{"tit for tat cooperating", history(10, 5, "C"), 200, 0.4, Cooperate},
+		{"end of tit for tat", history(24, 1, "D"), 200, 0.4, Defect},
+		{"rarely defects", history(25, 1, "D"), 200, 0.4, Cooperate},
+		{"always defects", history(25, 25, "D"), 200, 0.4, Defect},
+		{"defected at the threshold", history(25, 10, "D"), 200, 0.4, Cooperate},
+		{"defected over the threshold", history(25, 11, "D"), 200, 0.4, Defect},
+		{"over the threshold but just cooperated", history(25, 11, "C"), 200, 0.4, Cooperate},
+		{"under a higher threshold", history(25, 11, "D"), 200, 0.5, Cooperate},
+		{"no threshold", history(25, 1, "D"), 200, 0, Defect},
+		{"short game cooperating", "", 20, 0.4, Cooperate},
+		{"short game tit for tat", history(1, 1, "D"), 20, 0.4, Defect},
+		{"short game last phase", history(3, 1, "D"), 20, 0.4, Cooperate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, strings.Repeat("C", len(tt.opponent)))
+			state.rounds = tt.rounds
+			if got := (ChampionBot{Threshold: tt.threshold}).Decision(state); got != tt.want {
+				t.Errorf("got %c, want %c", moveLetter(got), moveLetter(tt.want))
+			}
+		})
+	}
+}
+
+func TestTidemanChieruzziFreshStart(t *testing.T) {
+	// the opponent alternating looks random, and defecting throughout puts
+	// the bot 55 points ahead
+	tests := []struct {
+		name          string
+		opponent, own string
+		rounds        int
+		lastFresh     int
+		fresh         bool
+	}{
+		{"ahead against random", strings.Repeat("DC", 11), strings.Repeat("D", 22), 100, 0, true},
+		{"behind", strings.Repeat("DC", 11), strings.Repeat("C", 22), 100, 0, false},
+		{"too soon after the last", strings.Repeat("DC", 11), strings.Repeat("D", 22), 100, 5, false},
+		{"near the end", strings.Repeat("DC", 11), strings.Repeat("D", 22), 30, 0, false},
+		{"not random", strings.Repeat("C", 22), strings.Repeat("D", 22), 100, 0, false},
+		{"new run of defections", strings.Repeat("DC", 10) + "CD", strings.Repeat("D", 22), 100, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, tt.own)
+			state.round, state.rounds = len(tt.opponent), tt.rounds
+			state.aPayoff, state.bPayoff = DefaultPayoff, DefaultPayoff
+			bot := &TidemanChieruzziBot{runs: 3, lastFresh: tt.lastFresh}
+
+			move := bot.Decision(state)
+			if !tt.fresh {
+				if bot.runs < 3 {
+					t.Errorf("forgot about %d runs of defections", 3-bot.runs)
+				}
+				return
+			}
+			if move != Cooperate || bot.runs != 0 || bot.retaliations != 0 || bot.lastFresh != len(tt.opponent) {
+				t.Errorf("played %c and kept %+v after a fresh start", moveLetter(move), *bot)
+			}
+			// the second cooperation comes even if the opponent defects
+			if move := bot.Decision(historyState(tt.opponent+"D", tt.own+"C")); move != Cooperate {
+				t.Errorf("fresh start ended after one cooperation")
+			}
+			// and the next run of defections gets a single retaliation
+			if move := bot.Decision(historyState(tt.opponent+"DC", tt.own+"CC")); move != Cooperate {
+				t.Errorf("retaliated against a cooperation")
+			}
+			state = historyState(tt.opponent+"DCD", tt.own+"CCC")
+			if bot.Decision(state) != Defect || bot.Decision(historyState(tt.opponent+"DCDC", tt.own+"CCCD")) != Cooperate {
+				t.Errorf("retaliation wasn't one round after a fresh start")
+			}
+		})
+	}
+}
+
+func TestGraaskampBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent Bot
+		periodic bool
+	}{
+		{"CooperateBot", CooperateBot{}, false},
+		{"TitForTatBot", TitForTatBot{}, true},
+		{"even mix", &ScriptedBot{Moves: moves("CDDCCDDC"), Loop: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(tt.opponent, GraaskampBot{}, GameOptions{Rounds: 100})
+			moves := movesString(game.BHistory)
+			if moves[50] != 'D' {
+				t.Errorf("didn't probe on round 51: %s", moves)
+			}
+			for n := 60; n < 100; n += 5 {
+				if (moves[n] == 'D') != tt.periodic {
+					t.Errorf("played %c on round %d: %s", moves[n], n+1, moves)
+				}
+			}
+			// nothing else for it to react to, so the probe is its only
+			// defection
+			if !tt.periodic && strings.Count(moves, "D") != 1 {
+				t.Errorf("defected more than once: %s", moves)
+			}
+		})
+	}
+}
+
+func TestNydeggerBot(t *testing.T) {
+	tests := []struct {
+		name          string
+		opponent, own string
+		index         int // -1 in the opening
+		want          int
+	}{
+		{"first round", "", "", -1, Cooperate},
+		{"tit for tat opening", "D", "C", -1, Defect},
+		{"opening exception after CD against DC", "DC", "CD", -1, Defect},
+		{"tit for tat second round", "CC", "CC", -1, Cooperate},
+		{"all cooperated", "CCC", "CCC", 0, Cooperate},
+		{"defected three rounds ago", "CCC", "DCC", 1, Defect},
+		{"opponent defected last round", "CCD", "CCC", 32, Cooperate},
+		{"defected two rounds ago", "DCC", "CDC", 6, Defect},
+		{"nearly all defections", "CDD", "DDD", 61, Defect},
+		{"all defections", "DDD", "DDD", 63, Cooperate},
+		{"only the last three count", "DDDCCC", "DDDDCC", 1, Defect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, tt.own)
+			if tt.index >= 0 {
+				if got := nydeggerIndex(state); got != tt.index {
+					t.Errorf("index %d, want %d", got, tt.index)
+				}
+			}
+			if got := (NydeggerBot{}).Decision(state); got != tt.want {
+				t.Errorf("got %c, want %c", moveLetter(got), moveLetter(tt.want))
+			}
+		})
+	}
+}
+
+func TestTullockBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent string
+		margin   float64
+		rate     float64 // chance of cooperating
+	}{
+		{"opening", strings.Repeat("D", 10), 0.1, 1},
+		{"always cooperated", strings.Repeat("C", 11), 0.1, 0.9},
+		{"cooperated seven of the last ten", "DDDDD" + "CCDCCDCCDC", 0.1, 0.6},
+		{"older rounds forgotten", "CCCCC" + "DDDDDDDDCC", 0.1, 0.1},
+		{"never below zero", strings.Repeat("D", 12), 0.1, 0},
+		{"bigger margin", strings.Repeat("C", 6) + "CCDCCDCCDC", 0.3, 0.4},
+		{"no margin", strings.Repeat("C", 6) + "CCDCCDCCDC", 0, 0.7},
+	}
+	const samples = 20000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, strings.Repeat("C", len(tt.opponent)))
+			bot := &TullockBot{Margin: tt.margin, SeededRand: SeededRand{Seed: 1}}
+			if len(tt.opponent) >= 11 {
+				if got := bot.Cooperation(state); math.Abs(got-tt.rate) > 1e-9 {
+					t.Errorf("Cooperation = %v, want %v", got, tt.rate)
+				}
+			}
+			cooperated := 0
+			for i := 0; i < samples; i++ {
+				if bot.Decision(state) == Cooperate {
+					cooperated++
+				}
+			}
+			if got := float64(cooperated) / samples; math.Abs(got-tt.rate) > 0.015 {
+				t.Errorf("cooperated %.3f of the time, want %.3f", got, tt.rate)
+			}
+		})
+	}
+}
+
+func TestNaiveProberBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent string
+		probe    float64
+		rate     float64 // chance of defecting
+	}{
+		{"first round", "", 0.3, 0},
+		{"retaliates", "CD", 0.3, 1},
+		{"retaliates without probing", "CD", 0, 1},
+		{"probes", "CC", 0.3, 0.3},
+		{"rarely probes", "DC", 0.05, 0.05},
+		{"never probes", "CC", 0, 0},
+	}
+	const samples = 20000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := historyState(tt.opponent, strings.Repeat("C", len(tt.opponent)))
+			bot := &NaiveProberBot{Probe: tt.probe, SeededRand: SeededRand{Seed: 1}}
+			defected := 0
+			for i := 0; i < samples; i++ {
+				if bot.Decision(state) == Defect {
+					defected++
+				}
+			}
+			if got := float64(defected) / samples; math.Abs(got-tt.rate) > 0.015 {
+				t.Errorf("defected %.3f of the time, want %.3f", got, tt.rate)
+			}
+		})
+	}
+
+	// over a whole game against a cooperator every defection is a probe
+	bot := &NaiveProberBot{Probe: 0.2, SeededRand: SeededRand{Seed: 2}}
+	game := PlayGame(CooperateBot{}, bot, GameOptions{Rounds: 5001})
+	if got := float64(countMoves(game.BHistory[1:], Defect)) / 5000; math.Abs(got-0.2) > 0.03 {
+		t.Errorf("probed %.3f of the time, want 0.2", got)
+	}
+}
+
+func TestNewNeuralNetworkBotFromReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		genome string
+		err    string
+	}{
+		{"champion", championGenome, ""},
+		{"empty", "", "building network"},
+		{"not a genome", "garbage", "reading genome"},
+		{"cut off", championGenome[:len(championGenome)/2], "reading genome"},
+		{"no output", strings.Replace(championGenome, "node 13 1 0 2", "node 13 1 0 0", 1), "building network"},
+		{"two outputs", strings.Replace(championGenome, "node 3 1 0 0", "node 3 1 0 2", 1), "has 2 outputs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, err := NewNeuralNetworkBotFromReader(strings.NewReader(tt.genome))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := PlayGame(bot, CooperateBot{}, GameOptions{}); len(got.AHistory) != DefaultRounds {
+					t.Errorf("played %d rounds", len(got.AHistory))
+				}
+				return
+			}
+			if err == nil || bot != nil {
+				t.Fatalf("got a bot and error %v, want an error mentioning %q", err, tt.err)
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error %q doesn't mention %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGetGenomeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		genome string
+		err    string
+	}{
+		{"truncated", championGenome[:len(championGenome)-40], "reading genome"},
+		{"missing node", strings.Replace(championGenome, "gene 1 2 3", "gene 1 2 99", 1), "gene 27 linking a node it doesn't have"},
+		{"no nodes", "genomestart 1\ngenomeend 1\n", "building network"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, err := getGenome(tt.genome)
+			if err == nil || net != nil {
+				t.Fatalf("got a network and error %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error %q doesn't mention %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFictitiousPlayBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent Bot
+		want     int // best response it should settle on
+	}{
+		// defecting is the best response to any fixed mix of moves
+		{"mostly cooperates", &ScriptedBot{Moves: moves("CCCD"), Loop: true}, Defect},
+		{"mostly defects", &ScriptedBot{Moves: moves("DDDC"), Loop: true}, Defect},
+		{"even mix", &ScriptedBot{Moves: moves("CDDCCDDC"), Loop: true}, Defect},
+		// but against tit for tat cooperating is what keeps it cooperating
+		{"TitForTatBot", TitForTatBot{}, Cooperate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(tt.opponent, FictitiousPlayBot{}, GameOptions{Rounds: 200})
+			if got := countMoves(game.BHistory[100:], tt.want); got < 95 {
+				t.Errorf("played %c in %d of the last 100 rounds: %s", moveLetter(tt.want), got, movesString(game.BHistory))
+			}
+		})
+	}
+
+	// two of three cooperations after it cooperated and one after its one
+	// defection, each with a cooperation and a defection added
+	state := historyState("CCDCC", "CCCDC")
+	if c, d := (FictitiousPlayBot{}).Frequencies(state); c != 3.0/5 || d != 2.0/3 {
+		t.Errorf("Frequencies = %v, %v, want %v, %v", c, d, 3.0/5, 2.0/3)
+	}
+}
+
+func TestAdaptiveGenerousTFTForgiveness(t *testing.T) {
+	tests := []struct {
+		name          string
+		opponent, own string
+		initial, step float64
+		want          float64
+	}{
+		{"no history", "", "", 0.1, 0.05, 0.1},
+		{"one mutual defection", "CD", "CD", 0.1, 0.05, 0.1},
+		{"defection spiral", "DDDDD", "CDDDD", 0.1, 0.05, 0.25},
+		{"exploited", "DDDD", "CCCC", 0.5, 0.1, 0.2},
+		{"never below zero", "DDDD", "CCCC", 0.1, 0.05, 0},
+		{"never above one", "DDDDD", "CDDDD", 0.9, 0.1, 1},
+		{"spiral then exploited", "DDDD", "CCDD", 0.1, 0.05, 0.1},
+		{"starts unforgiving", "DDDDD", "CDDDD", 0, 0.05, 0.15},
+		{"never adapts", "DDDDD", "CDDDD", 0.1, 0, 0.1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &AdaptiveGenerousTFTBot{Initial: tt.initial, Step: tt.step}
+			if got := bot.Forgiveness(historyState(tt.opponent, tt.own)); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Forgiveness = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdaptiveBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent Bot
+		want     int
+	}{
+		// defecting against a cooperator is worth the temptation every round
+		{"CooperateBot", CooperateBot{}, Defect},
+		// tit for tat answers the defections so cooperating pays more
+		{"TitForTatBot", TitForTatBot{}, Cooperate},
+		{"DefectBot", DefectBot{}, Defect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(AdaptiveBot{}, tt.opponent, GameOptions{Rounds: 40})
+			if opening := movesString(game.AHistory[:len(adaptiveOpening)]); opening != movesString(adaptiveOpening) {
+				t.Errorf("opened with %s, want %s", opening, movesString(adaptiveOpening))
+			}
+			// a round for the opponent to answer the last of the opening
+			// and another for the averages to settle
+			for i, move := range game.AHistory[len(adaptiveOpening)+2:] {
+				if move != tt.want {
+					t.Fatalf("round %d played %c, want %c settled on\n%s", len(adaptiveOpening)+2+i, moveLetter(move), moveLetter(tt.want), movesString(game.AHistory))
+				}
+			}
+		})
+	}
+}
+
+func TestAdaptiveBotAverages(t *testing.T) {
+	state := historyState("CCDD", "CDCD")
+	state.bPayoff = DefaultPayoff
+	cooperate, defect := AdaptiveBot{}.Averages(state)
+	// cooperating met a cooperation and a defection, defecting the same
+	wantCooperate := float64(DefaultPayoff.Reward+DefaultPayoff.Sucker) / 2
+	wantDefect := float64(DefaultPayoff.Temptation+DefaultPayoff.Punishment) / 2
+	if cooperate != wantCooperate || defect != wantDefect {
+		t.Errorf("Averages = %v, %v, want %v, %v", cooperate, defect, wantCooperate, wantDefect)
+	}
+}
+
+func TestForgivingGrudgerBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		punish   int
+		opponent string
+		want     string
+	}{
+		{"four retaliations then forgiven", 4, "CDCCCCCCCC", "CCDDDDCCCC"},
+		{"one is tit for tat", 1, "CDCCCCCCCC", "CCDCCCCCCC"},
+		{"none never retaliates", 0, "CDCCDCCCCC", "CCCCCCCCCC"},
+		// defections during the punishment don't start it again
+		{"defection while punishing", 4, "CDCDCCCCCC", "CCDDDDCCCC"},
+		{"defection after forgiving", 2, "CDCCDCCCCC", "CCDDCDDCCC"},
+		{"as long as the game is grim", 10, "CDCCCCCCCC", "CCDDDDDDDD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(ForgivingGrudgerBot{Punish: tt.punish}, scripted(tt.opponent), GameOptions{Rounds: len(tt.opponent)})
+			if got := movesString(game.AHistory); got != tt.want {
+				t.Errorf("played %s against %s, want %s", got, tt.opponent, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverityGrimBot(t *testing.T) {
+	harsh := Payoff{Temptation: 3, Reward: 1, Punishment: -1, Sucker: -5}
+	tests := []struct {
+		name      string
+		own       string // what it played each round while the opponent defected
+		payoff    Payoff
+		threshold float64
+		want      int // defections it takes to trigger
+	}{
+		// each costs the reward less the sucker's payoff, 3
+		{"suckered", "C", DefaultPayoff, 6, 2},
+		// each costs the reward less the punishment, 2
+		{"mutual defection", "D", DefaultPayoff, 6, 3},
+		{"suckered harshly", "C", harsh, 6, 1},
+		{"mutual defection harshly", "D", harsh, 6, 3},
+		{"lower threshold", "D", DefaultPayoff, 4, 2},
+		// with no threshold there is nothing to forgive before defecting
+		{"no threshold", "C", DefaultPayoff, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for n := 0; n <= 10; n++ {
+				state := historyState(strings.Repeat("D", n), strings.Repeat(tt.own, n))
+				state.bPayoff = tt.payoff
+				if (SeverityGrimBot{Threshold: tt.threshold}).Decision(state) == Defect {
+					if n != tt.want {
+						t.Errorf("triggered after %d defections, want %d", n, tt.want)
+					}
+					return
+				}
+			}
+			t.Error("never triggered")
+		})
+	}
+}
+
+func TestPenanceBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		penance  int
+		opponent string // its moves after the bot's mistaken defection
+		want     string
+	}{
+		{"punished", 3, "DDDD", "CCCD"},
+		{"forgiven", 3, "CCCC", "CCCC"},
+		{"one", 1, "DDDD", "CD" + "CD"},
+		{"long", 5, "DDDDDD", "CCCCCD"},
+		// plain tit for tat answers as soon as it sees the punishment
+		{"none", 0, "DDDD", "CDDD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := PenanceBot{Penance: tt.penance}
+			// it defected by mistake in the third round
+			opponent, own := "CCC", "CCD"
+			for _, move := range tt.opponent {
+				got := bot.Decision(historyState(opponent, own))
+				opponent, own = opponent+string(move), own+string(moveLetter(got))
+			}
+			if got := own[3:]; got != tt.want {
+				t.Errorf("played %s after defecting against %s, want %s", got, tt.opponent, tt.want)
+			}
+		})
+	}
+}
+
+func TestProberFamilyBot(t *testing.T) {
+	tests := []struct {
+		name     string
+		bot      ProberFamilyBot
+		opponent string
+		want     string
+	}{
+		{"Prober exploits a pushover", NewProberBot(), "CCCCCCC", "DCCDDDD"},
+		{"Prober plays tit for tat with a retaliator", NewProberBot(), "CDCCCCC", "DCCCCCC"},
+		{"Prober2 trusts a reciprocator", NewProber2Bot(), "CDCDDDD", "DCCCCCC"},
+		{"Prober2 plays tit for tat with a pushover", NewProber2Bot(), "CCCDCCC", "DCCCDCC"},
+		{"Prober3 exploits a pushover", NewProber3Bot(), "CCDDDD", "DCDDDD"},
+		{"Prober3 plays tit for tat with a retaliator", NewProber3Bot(), "CDCCCC", "DCDCCC"},
+		{"no probe is tit for tat", ProberFamilyBot{ExploitIfLenient: true}, "CCDCC", "CCCDC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(tt.bot, scripted(tt.opponent), GameOptions{Rounds: len(tt.opponent)})
+			if got := movesString(game.AHistory); got != tt.want {
+				t.Errorf("played %s against %s, want %s", got, tt.opponent, tt.want)
+			}
+		})
+	}
+}
+
+// draws takes n numbers from a bot's random source
+func draws(s *SeededRand, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = s.Float64()
+	}
+	return out
+}
+
+func sameDraws(a, b []float64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSeededRand(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  uint64
+		equal bool
+	}{
+		{"same seed", 42, 42, true},
+		{"different seeds", 42, 43, false},
+		{"unseeded", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &SeededRand{Seed: tt.a}
+			b := &SeededRand{Seed: tt.b}
+			if got := sameDraws(draws(a, 20), draws(b, 20)); got != tt.equal {
+				t.Errorf("same draws = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestSeededRandReseed(t *testing.T) {
+	s := &SeededRand{Seed: 7}
+	first := draws(s, 10)
+	s.Reseed(7)
+	if !sameDraws(first, draws(s, 10)) {
+		t.Error("reseeding with the same seed should replay the same draws")
+	}
+}
+
+func TestUnseededRandomBotsAreIndependent(t *testing.T) {
+	game := PlayGame(&RandomBot{}, &RandomDefectBot{Rate: 0.5}, GameOptions{Rounds: 1000})
+	split := 0
+	for i := range game.AHistory {
+		if game.AHistory[i] != game.BHistory[i] {
+			split++
+		}
+	}
+	// independent coin flips split about half the time
+	if split < 400 || split > 600 {
+		t.Errorf("%d of 1000 rounds split, want about 500", split)
+	}
+}
+
+func TestRandomDefectBotRate(t *testing.T) {
+	tests := []struct {
+		name string
+		bot  Bot
+		want string
+	}{
+		{"never", &RandomDefectBot{Rate: 0}, "CCCCCCCCCC"},
+		{"always", &RandomDefectBot{Rate: 1}, "DDDDDDDDDD"},
+		{"often never", &OftenRandomDefectBot{Rate: 0}, "CCCCCCCCCC"},
+		{"often always", &OftenRandomDefectBot{Rate: 1}, "DDDDDDDDDD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := movesString(PlayGame(tt.bot, CooperateBot{}, GameOptions{}).AHistory); got != tt.want {
+				t.Errorf("played %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeededBotsReplay(t *testing.T) {
+	tests := []struct {
+		name string
+		bot  func(seed uint64) Bot
+	}{
+		{"RandomBot", func(seed uint64) Bot { return &RandomBot{SeededRand{Seed: seed}} }},
+		{"RandomDefectBot", func(seed uint64) Bot { return &RandomDefectBot{Rate: 1.0 / 10, SeededRand: SeededRand{Seed: seed}} }},
+		{"OftenRandomDefectBot", func(seed uint64) Bot { return &OftenRandomDefectBot{Rate: 1.0 / 3, SeededRand: SeededRand{Seed: seed}} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := GameOptions{Rounds: 200}
+			a := PlayGame(tt.bot(9), CooperateBot{}, opts)
+			b := PlayGame(tt.bot(9), CooperateBot{}, opts)
+			if movesString(a.AHistory) != movesString(b.AHistory) {
+				t.Errorf("same seed played\n%s\n%s", movesString(a.AHistory), movesString(b.AHistory))
+			}
+			c := PlayGame(tt.bot(10), CooperateBot{}, opts)
+			if movesString(a.AHistory) == movesString(c.AHistory) {
+				t.Error("different seeds played the same moves")
+			}
+		})
+	}
+}
+
+func TestFixedMixedBot(t *testing.T) {
+	tests := []struct {
+		coopProb float64
+	}{
+		{0}, {0.3}, {0.5}, {1},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.coopProb), func(t *testing.T) {
+			bot := &FixedMixedBot{CoopProb: tt.coopProb}
+			const games = 2000
+			cooperators := 0
+			for i := 0; i < games; i++ {
+				reseedBot(bot, uint64(i+1))
+				game := PlayGame(bot, TitForTatBot{}, GameOptions{})
+				moves := movesString(game.AHistory)
+				if strings.Trim(moves, moves[:1]) != "" {
+					t.Fatalf("game %d: switched type mid game, played %s", i, moves)
+				}
+				if moves[0] == 'C' {
+					cooperators++
+				}
+			}
+			if got := float64(cooperators) / games; math.Abs(got-tt.coopProb) > 0.05 {
+				t.Errorf("cooperated in %.3f of games, want about %.3f", got, tt.coopProb)
+			}
+		})
+	}
+}
+
+func TestFixedMixedBotSelfPlayDrawsEachSeat(t *testing.T) {
+	bots := map[string]Bot{"FixedMixedBot": &FixedMixedBot{CoopProb: 0.5}}
+	result, err := RunTournament(context.Background(), bots, TournamentOptions{Games: 1000, Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := result.Matchups[0]
+	// independent draws both cooperate a quarter of the time
+	if got := float64(m.MutualCooperations) / float64(m.Rounds); math.Abs(got-0.25) > 0.05 {
+		t.Errorf("mutual cooperation in %.3f of rounds, want about 0.25", got)
+	}
+}
+
+func TestSoftReset(t *testing.T) {
+	tests := []struct {
+		name     string
+		bot      Bot
+		opponent string
+		want     string
+	}{
+		{"GradualBot", GradualBot{}, "DCCCCCCCDCCCCCCCDCCCCCCC", "CDCCCCCCCDDCCCCCCDDDCCCC"},
+		// every punishment has been forgiven by the next defection
+		{"GradualBot forgiving", GradualBot{SoftReset{After: 2}}, "DCCCCCCCDCCCCCCCDCCCCCCC", "CDCCCCCCCDCCCCCCCDCCCCCC"},
+		{"ThresholdGrimBot", ThresholdGrimBot{K: 2}, "DCCCCDCCCCCC", "CCCCCCDDDDDD"},
+		{"ThresholdGrimBot forgiving", ThresholdGrimBot{K: 2, SoftReset: SoftReset{After: 3}}, "DCCCCDCCCCCC", "CCCCCCCCCCCC"},
+		// the run of cooperation is broken before it is long enough, so it
+		// triggers until the next run has forgiven the first defection
+		{"ThresholdGrimBot short run", ThresholdGrimBot{K: 2, SoftReset: SoftReset{After: 3}}, "DCCDCCCC", "CCCCDDDC"},
+		{"GrimBot", GrimBot{}, "DCCCCCCC", "CDDDDDDD"},
+		// triggered, it goes back to cooperating once the opponent has
+		// cooperated three rounds while it was defecting
+		{"GrimBot forgiving", GrimBot{SoftReset{After: 3}}, "DCCCCCCC", "CDDDCCCC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(scripted(tt.opponent), tt.bot, GameOptions{Rounds: len(tt.opponent)})
+			if got := movesString(game.BHistory); got != tt.want {
+				t.Errorf("against %s played %s, want %s", tt.opponent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImitatorBot(t *testing.T) {
+	board := NewScoreboard(map[string]func() Bot{
+		"alternate":    func() Bot { return &ScriptedBot{Moves: []int{Cooperate, Defect}, Loop: true} },
+		"DefectBot":    func() Bot { return DefectBot{} },
+		"TitForTatBot": func() Bot { return TitForTatBot{} },
+	})
+	tests := []struct {
+		leader string
+		want   string
+	}{
+		// each imitator steps through a script of its own
+		{"alternate", "CDCDCDCDCD"},
+		{"DefectBot", "DDDDDDDDDD"},
+		{"TitForTatBot", "CCCCCCCCCC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.leader, func(t *testing.T) {
+			board.Set(tt.leader, 10)
+			defer board.Set(tt.leader, 0)
+
+			game := PlayGame(&ImitatorBot{Scoreboard: board}, &ImitatorBot{Scoreboard: board}, GameOptions{})
+			if a, b := movesString(game.AHistory), movesString(game.BHistory); a != tt.want || b != tt.want {
+				t.Errorf("imitators played %s and %s, want %s", a, b, tt.want)
+			}
+		})
+	}
+}
+
+func TestReputationBot(t *testing.T) {
+	reputations := NewReputations()
+	reputations.Set("DefectBot", 0)
+	reputations.Set("CooperateBot", 1)
+	bots := map[string]Bot{
+		"CooperateBot":  CooperateBot{},
+		"DefectBot":     DefectBot{},
+		"ReputationBot": &ReputationBot{Reputations: reputations, Threshold: 0.5},
+		"stranger":      TitForTatBot{},
+	}
+	result, err := RunTournament(context.Background(), bots, TournamentOptions{Games: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		opponent string
+		want     float64 // how often ReputationBot cooperates against it
+	}{
+		{"CooperateBot", 1},
+		{"DefectBot", 0},
+		// nothing is known about it so it gets the benefit of the doubt
+		{"stranger", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.opponent, func(t *testing.T) {
+			for _, m := range result.Matchups {
+				var cooperations int
+				switch {
+				case m.A == "ReputationBot" && m.B == tt.opponent:
+					cooperations = m.ACooperations
+				case m.B == "ReputationBot" && m.A == tt.opponent:
+					cooperations = m.BCooperations
+				default:
+					continue
+				}
+				if got := float64(cooperations) / float64(m.Moves); got != tt.want {
+					t.Errorf("cooperated in %.2f of rounds, want %.2f", got, tt.want)
+				}
+				return
+			}
+			t.Fatal("no matchup played")
+		})
+	}
+}
+
+func TestReputationBotThreshold(t *testing.T) {
+	reputations := NewReputations()
+	reputations.Set("low", 0.59)
+	reputations.Set("at", 0.6)
+	reputations.Set("high", 0.9)
+	tests := []struct {
+		opponent string
+		want     int
+	}{
+		{"low", Defect},
+		// reaching the threshold is enough
+		{"at", Cooperate},
+		{"high", Cooperate},
+		{"stranger", Cooperate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.opponent, func(t *testing.T) {
+			bot := &ReputationBot{Reputations: reputations, Threshold: 0.6}
+			bot.Meet(tt.opponent)
+			if got := bot.Decision(GameState{}); got != tt.want {
+				t.Errorf("played %c, want %c", moveLetter(got), moveLetter(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/sbinet/npyio/npy"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CooperationMatrix is how often each pair of bots both cooperated, often
+// clearer than the scores for picking out clusters of bots that get on
+type CooperationMatrix struct {
+	Names []string
+	// Rates is the share of rounds both cooperated in with the row bot as
+	// player A and the column bot as player B, NaN if they never played
+	Rates [][]float64
+}
+
+// MutualCooperationRate is the share of scored rounds both bots cooperated in
+func (m MatchupResult) MutualCooperationRate() float64 {
+	if m.Rounds == 0 {
+		return 0
+	}
+	return float64(m.MutualCooperations) / float64(m.Rounds)
+}
+
+// CooperationMatrix lays out the mutual cooperation rate of every matchup
+func (r TournamentResult) CooperationMatrix() CooperationMatrix {
+	names := r.Bots()
+	index := indexNames(names)
+
+	rates := make([][]float64, len(names))
+	for i := range rates {
+		rates[i] = make([]float64, len(names))
+		for j := range rates[i] {
+			rates[i][j] = math.NaN()
+		}
+	}
+	for _, m := range r.Matchups {
+		if m.Rounds > 0 {
+			rates[index[m.A]][index[m.B]] = m.MutualCooperationRate()
+		}
+	}
+	return CooperationMatrix{Names: names, Rates: rates}
+}
+
+// Write saves the matrix to path. A path ending in .csv gets a header row
+// and first column of names, anything else is written as a .npy matrix with
+// the names one per line in a sidecar file ending in .names.txt like
+// Ecology.Write
+func (c CooperationMatrix) Write(path string) error {
+	if len(c.Names) == 0 {
+		return errors.New("no bots to write")
+	}
+	if strings.HasSuffix(path, ".csv") {
+		return c.writeCSV(path)
+	}
+
+	data := make([]float64, 0, len(c.Names)*len(c.Names))
+	for _, row := range c.Rates {
+		data = append(data, row...)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := npy.Write(file, mat.NewDense(len(c.Names), len(c.Names), data)); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return writeLines(strings.TrimSuffix(path, ".npy")+".names.txt", c.Names)
+}
+
+func (c CooperationMatrix) writeCSV(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write(append([]string{""}, c.Names...)); err != nil {
+		return err
+	}
+	for i, row := range c.Rates {
+		record := []string{c.Names[i]}
+		for _, rate := range row {
+			record = append(record, strconv.FormatFloat(rate, 'f', -1, 64))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"github.com/sbinet/npyio/npy"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCooperationMatrix(t *testing.T) {
+	bots := map[string]Bot{"CooperateBot": CooperateBot{}, "DefectBot": DefectBot{}, "ThresholdGrimBot": ThresholdGrimBot{K: 1}, "TitForTatBot": TitForTatBot{}}
+	result, err := RunTournament(context.Background(), bots, TournamentOptions{Games: 2, GameOptions: GameOptions{Rounds: 20}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrix := result.CooperationMatrix()
+
+	// anyone playing DefectBot never gets a round of cooperation in, the
+	// rest cooperate throughout
+	want := [][]float64{
+		{1, 0, 1, 1},
+		{0, 0, 0, 0},
+		{1, 0, 1, 1},
+		{1, 0, 1, 1},
+	}
+	if !reflect.DeepEqual(matrix.Names, []string{"CooperateBot", "DefectBot", "ThresholdGrimBot", "TitForTatBot"}) {
+		t.Fatalf("names %v", matrix.Names)
+	}
+	if !reflect.DeepEqual(matrix.Rates, want) {
+		t.Errorf("rates %v, want %v", matrix.Rates, want)
+	}
+	for i := range matrix.Rates {
+		for j := range matrix.Rates {
+			if matrix.Rates[i][j] != matrix.Rates[j][i] {
+				t.Errorf("%s against %s is %v but the other way round is %v",
+					matrix.Names[i], matrix.Names[j], matrix.Rates[i][j], matrix.Rates[j][i])
+			}
+		}
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cooperation.csv")
+		if err := matrix.Write(path); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantCSV := strings.Join([]string{
+			",CooperateBot,DefectBot,ThresholdGrimBot,TitForTatBot",
+			"CooperateBot,1,0,1,1",
+			"DefectBot,0,0,0,0",
+			"ThresholdGrimBot,1,0,1,1",
+			"TitForTatBot,1,0,1,1",
+		}, "\n") + "\n"
+		if string(data) != wantCSV {
+			t.Errorf("wrote\n%s\nwant\n%s", data, wantCSV)
+		}
+	})
+
+	t.Run("npy", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cooperation.npy")
+		if err := matrix.Write(path); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		var m mat.Dense
+		if err := npy.Read(file, &m); err != nil {
+			t.Fatal(err)
+		}
+		if rows, cols := m.Dims(); rows != len(want) || cols != len(want) {
+			t.Fatalf("read a %dx%d matrix, want %dx%d", rows, cols, len(want), len(want))
+		}
+		for i := range want {
+			if got := m.RawRowView(i); !reflect.DeepEqual(got, want[i]) {
+				t.Errorf("row %d is %v, want %v", i, got, want[i])
+			}
+		}
+	})
+}
+
+func TestCooperationMatrixUnplayed(t *testing.T) {
+	result := TournamentResult{Matchups: []MatchupResult{{A: "CooperateBot", B: "DefectBot", Games: 1, Rounds: 11}}}
+	matrix := result.CooperationMatrix()
+	if got := matrix.Rates[0][1]; got != 0 {
+		t.Errorf("played pair has rate %v, want 0", got)
+	}
+	for _, cell := range [][2]int{{0, 0}, {1, 0}, {1, 1}} {
+		if got := matrix.Rates[cell[0]][cell[1]]; !math.IsNaN(got) {
+			t.Errorf("unplayed %s against %s has rate %v, want NaN", matrix.Names[cell[0]], matrix.Names[cell[1]], got)
+		}
+	}
+
+	if err := (CooperationMatrix{}).Write(filepath.Join(t.TempDir(), "empty.csv")); err == nil {
+		t.Error("wrote a matrix with no bots")
+	}
+}
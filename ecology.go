@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/sbinet/npyio/npy"
+	"gonum.org/v1/gonum/mat"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Ecology is how the share of a population playing each strategy changes
+// over the generations when every bot's offspring is in proportion to how
+// well it scored against the population in the tournament
+type Ecology struct {
+	Names       []string    // strategies, the same order as each generation
+	Proportions [][]float64 // share of each strategy, one row per generation
+}
+
+// ReplicatorDynamics runs an ecological simulation off the back of a
+// tournament. Every strategy starts with an equal share and each generation
+// it grows by how many points per game it scores against the current
+// population
+func ReplicatorDynamics(result TournamentResult, generations int) Ecology {
+	names := result.Bots()
+	index := map[string]int{}
+	for i, name := range names {
+		index[name] = i
+	}
+
+	// average points per game row scored against column
+	payoff := make([][]float64, len(names))
+	for i := range payoff {
+		payoff[i] = make([]float64, len(names))
+	}
+	lowest := 0.0
+	for _, m := range result.Matchups {
+		if m.Games == 0 {
+			continue
+		}
+		a, b := index[m.A], index[m.B]
+		if a == b {
+			payoff[a][a] = float64(m.AScore+m.BScore) / float64(2*m.Games)
+		} else {
+			payoff[a][b] = float64(m.AScore) / float64(m.Games)
+			payoff[b][a] = float64(m.BScore) / float64(m.Games)
+		}
+		if payoff[a][b] < lowest {
+			lowest = payoff[a][b]
+		}
+		if payoff[b][a] < lowest {
+			lowest = payoff[b][a]
+		}
+	}
+
+	ecology := Ecology{Names: names}
+	if len(names) == 0 {
+		return ecology
+	}
+
+	current := make([]float64, len(names))
+	for i := range current {
+		current[i] = 1 / float64(len(names))
+	}
+	ecology.Proportions = append(ecology.Proportions, current)
+
+	for g := 1; g < generations; g++ {
+		next := make([]float64, len(names))
+		total := 0.0
+		for i := range names {
+			// the payoff can be negative so shift it up, otherwise a
+			// strategy could end up with a negative share
+			fitness := 0.0
+			for j := range names {
+				fitness += current[j] * (payoff[i][j] - lowest)
+			}
+			next[i] = current[i] * fitness
+			total += next[i]
+		}
+		if total == 0 {
+			// everyone scored the lowest possible so nothing changes
+			copy(next, current)
+		} else {
+			for i := range next {
+				next[i] /= total
+			}
+		}
+		ecology.Proportions = append(ecology.Proportions, next)
+		current = next
+	}
+
+	return ecology
+}
+
+// Write saves the proportions to path as a generations by strategies .npy
+// matrix, with the strategy names one per line in a sidecar file of the same
+// name ending in .names.txt
+func (e Ecology) Write(path string) error {
+	if len(e.Proportions) == 0 || len(e.Names) == 0 {
+		return errors.New("no generations to write")
+	}
+
+	data := make([]float64, 0, len(e.Proportions)*len(e.Names))
+	for _, row := range e.Proportions {
+		data = append(data, row...)
+	}
+	m := mat.NewDense(len(e.Proportions), len(e.Names), data)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := npy.Write(file, m); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return writeLines(strings.TrimSuffix(path, ".npy")+".names.txt", e.Names)
+}
+
+func writeLines(path string, lines []string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// IsESS checks whether a population of incumbent could be invaded by any of
+// the mutants, comparing the mean score per game over opts.Games games
+// (100,000 if unset) of each pairing. The incumbent is evolutionarily stable
+// against a mutant if it does better against itself than the mutant does
+// against it, or as well and then better against the mutant than the mutant
+// does against itself. The reason says which mutant invades and how, or
+// that none can
+func IsESS(incumbent Bot, mutants []Bot, opts TournamentOptions) (bool, string) {
+	games := opts.Games
+	if games <= 0 {
+		games = 100_000
+	}
+
+	// a second instance for each bot playing itself, so the two seats
+	// don't share any state
+	ii := meanScore(incumbent, cloneBot(incumbent), games, opts.GameOptions)
+	for _, mutant := range mutants {
+		mi := meanScore(mutant, incumbent, games, opts.GameOptions)
+		if mi > ii {
+			return false, fmt.Sprintf("%s scores %.3f against %s, which only scores %.3f against itself",
+				botType(mutant), mi, botType(incumbent), ii)
+		}
+		if mi < ii {
+			continue
+		}
+
+		im := meanScore(incumbent, mutant, games, opts.GameOptions)
+		mm := meanScore(mutant, cloneBot(mutant), games, opts.GameOptions)
+		if mm >= im {
+			return false, fmt.Sprintf("%s does as well as %s against it and scores %.3f against itself to its %.3f",
+				botType(mutant), botType(incumbent), mm, im)
+		}
+	}
+	return true, fmt.Sprintf("%s can't be invaded by any of the %d mutants", botType(incumbent), len(mutants))
+}
+
+// meanScore is a's mean score per game playing b as player A
+func meanScore(a, b Bot, games int, opts GameOptions) float64 {
+	total := 0
+	for i := 0; i < games; i++ {
+		total += PlayGame(a, b, opts).AScore
+	}
+	return float64(total) / float64(games)
+}
+
+// botType is the name of the bot's type, without the package or pointer
+func botType(b Bot) string {
+	t := reflect.TypeOf(b)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
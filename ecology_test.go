@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"github.com/sbinet/npyio/npy"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEcologyWrite(t *testing.T) {
+	bots := map[string]Bot{"CooperateBot": CooperateBot{}, "DefectBot": DefectBot{}, "TitForTatBot": TitForTatBot{}}
+	result, err := RunTournament(context.Background(), bots, TournamentOptions{Games: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecology := ReplicatorDynamics(result, 50)
+
+	path := filepath.Join(t.TempDir(), "ecology.npy")
+	if err := ecology.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var m mat.Dense
+	if err := npy.Read(file, &m); err != nil {
+		t.Fatal(err)
+	}
+	if rows, cols := m.Dims(); rows != 50 || cols != len(bots) {
+		t.Fatalf("read a %dx%d matrix, want 50x%d", rows, cols, len(bots))
+	}
+	for i := 0; i < 50; i++ {
+		if sum := mat.Sum(m.RowView(i)); math.Abs(sum-1) > 1e-9 {
+			t.Errorf("generation %d adds up to %v", i, sum)
+		}
+		if !reflect.DeepEqual(mat.Row(nil, i, &m), ecology.Proportions[i]) {
+			t.Errorf("generation %d read back as %v, want %v", i, mat.Row(nil, i, &m), ecology.Proportions[i])
+		}
+	}
+
+	names, err := os.ReadFile(filepath.Join(filepath.Dir(path), "ecology.names.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Fields(string(names)); !reflect.DeepEqual(got, ecology.Names) {
+		t.Errorf("names %v, want %v", got, ecology.Names)
+	}
+
+	if err := (Ecology{}).Write(path); err == nil {
+		t.Error("wrote an ecology with no generations")
+	}
+}
+
+func TestIsESS(t *testing.T) {
+	alternate := func() Bot { return &ScriptedBot{Moves: []int{Cooperate, Defect}, Loop: true} }
+	tests := []struct {
+		name      string
+		incumbent Bot
+		mutant    Bot
+		rounds    int
+		want      bool
+	}{
+		// ALLD scores P every round against itself but TFT is suckered in
+		// the first round against it, so ALLD is stable in any finite game
+		{"ALLD against TFT short", DefectBot{}, TitForTatBot{}, 5, true},
+		{"ALLD against TFT long", DefectBot{}, TitForTatBot{}, 200, true},
+		// TFT gets R every round against itself, ALLD only gets T once
+		{"TFT against ALLD one round", TitForTatBot{}, DefectBot{}, 1, false},
+		{"TFT against ALLD long", TitForTatBot{}, DefectBot{}, 200, true},
+		{"ALLC against ALLD", CooperateBot{}, DefectBot{}, 10, false},
+		// against a copy of itself it alternates CC and DD, which a
+		// cooperator can't match, sharing one instance it would look
+		// like it only ever got suckered
+		{"stateful incumbent", alternate(), CooperateBot{}, 11, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := TournamentOptions{Games: 1, GameOptions: GameOptions{Rounds: tt.rounds}}
+			got, reason := IsESS(tt.incumbent, []Bot{tt.mutant}, opts)
+			if got != tt.want {
+				t.Errorf("IsESS = %v, want %v: %s", got, tt.want, reason)
+			}
+		})
+	}
+}
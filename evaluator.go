@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sbinet/npyio/npz"
+	"github.com/yaricom/goNEAT/v2/experiment"
+	"github.com/yaricom/goNEAT/v2/neat"
+	"github.com/yaricom/goNEAT/v2/neat/genetics"
+	"github.com/yaricom/goNEAT/v2/neat/network"
+	"os"
+	"time"
+)
+
+type PrisonersDilemmaGenerationEvaluator struct {
+	GameOptions
+	// Opponents are the bots each organism plays a game against, its fitness
+	// is the weighted sum of its scores. Just CooperateBot if empty
+	Opponents []Opponent
+	// CacheFitness evaluates structurally identical organisms in a
+	// generation only once. Only use it when every opponent is deterministic,
+	// otherwise the copies would all share one lucky or unlucky score
+	CacheFitness bool
+	// Normalize makes fitness the average points per round per opponent
+	// rather than the weighted total, so it doesn't grow with the number of
+	// rounds or opponents
+	Normalize bool
+	// Endgame if set adds a BackwardInductionBot that defects over the last
+	// Endgame rounds to the opponents, so organisms have to cope with being
+	// betrayed at the end of the game
+	Endgame int
+	// Logger gets told about new winners and the best genome being saved,
+	// goNEAT's own logging is used if unset
+	Logger Logger
+	// ExportJSON also saves the best genome as GenomeJSON to best.json, for
+	// tools that can't read goNEAT's format
+	ExportJSON bool
+	// ExportTable also saves the memory one table the best genome's network
+	// plays to best.npz under "table", which LoadTableBot can read
+	ExportTable bool
+	// Sensors are the inputs organisms get, DefaultSensors if unset.
+	// Training starts from genomes with an input for each of them
+	Sensors SensorSet
+	// Diversity works out PopulationDiversity after every generation and
+	// logs it, to catch the population converging too early. goNEAT's own
+	// Generation.Diversity is the number of species rather than how
+	// differently they play
+	Diversity bool
+	// Context stops a generation part way through evaluating it once it is
+	// done, goNEAT only checks between generations. train sets it to the
+	// context it is given if unset
+	Context context.Context
+	// Games is how many games an organism plays against each opponent, its
+	// score against the opponent being the mean, 1 if unset
+	Games int
+	// Budget if set is how long evaluating a generation should take. Once
+	// it is used up the rest of the generation only plays one game against
+	// each opponent and a warning is logged
+	Budget time.Duration
+
+	// games is Games for the generation being evaluated, cut down once the
+	// Budget runs out
+	games int
+}
+
+// winnerScore is the score against each opponent over DefaultRounds an
+// organism needs to beat to be a winner
+const winnerScore = 20
+
+// fitnessCache holds the fitness of every genome evaluated so far in a
+// generation
+type fitnessCache struct {
+	results map[uint64]cachedFitness
+	hits    int
+}
+
+type cachedFitness struct {
+	fitness  float64
+	isWinner bool
+}
+
+func newFitnessCache() *fitnessCache {
+	return &fitnessCache{results: map[uint64]cachedFitness{}}
+}
+
+// Opponent is a bot organisms are trained against and how much its game
+// counts towards their fitness
+type Opponent struct {
+	Bot    Bot
+	Weight float64 // 1 if unset
+}
+
+func (o Opponent) weight() float64 {
+	if o.Weight == 0 {
+		return 1
+	}
+	return o.Weight
+}
+
+func (ex PrisonersDilemmaGenerationEvaluator) GenerationEvaluate(
+	pop *genetics.Population,
+	epoch *experiment.Generation,
+	context *neat.Options,
+) (err error) {
+	// Calculate the fitness of all organisms in the population
+	// going to fight against the opponents
+	var cache *fitnessCache
+	if ex.CacheFitness {
+		cache = newFitnessCache()
+	}
+	ex.games = ex.Games
+	start := time.Now()
+	for _, org := range pop.Organisms {
+		if ex.Context != nil && ex.Context.Err() != nil {
+			return ex.Context.Err()
+		}
+		if ex.Budget > 0 && ex.games > 1 && time.Since(start) > ex.Budget {
+			ex.games = 1
+			ex.log(Event{
+				Level:      LogProgress,
+				Message:    fmt.Sprintf("over the %v budget, playing one game per opponent for the rest of the generation", ex.Budget),
+				Trial:      epoch.TrialId,
+				Generation: epoch.Id,
+			})
+		}
+		res, err := ex.cachedEvaluate(org, cache)
+		if err != nil {
+			return err
+		}
+
+		if res && (epoch.Best == nil || org.Fitness > epoch.Best.Fitness) {
+			epoch.Solved = true
+			epoch.WinnerNodes = len(org.Genotype.Nodes)
+			epoch.WinnerGenes = org.Genotype.Extrons()
+			epoch.WinnerEvals = context.PopSize*epoch.Id + org.Genotype.Id
+			epoch.Best = org
+			ex.log(Event{
+				Level:      LogProgress,
+				Message:    "new winner",
+				Trial:      epoch.TrialId,
+				Generation: epoch.Id,
+				Genome:     org.Genotype.Id,
+				Fitness:    org.Fitness,
+				Nodes:      epoch.WinnerNodes,
+				Genes:      epoch.WinnerGenes,
+			})
+		}
+	}
+
+	epoch.FillPopulationStatistics(pop)
+
+	if ex.Diversity {
+		diversity, err := PopulationDiversity(pop, ex.Sensors)
+		event := Event{
+			Level:      LogProgress,
+			Message:    fmt.Sprintf("behavioral diversity %.3f", diversity),
+			Trial:      epoch.TrialId,
+			Generation: epoch.Id,
+			Diversity:  diversity,
+		}
+		if err != nil {
+			event.Message = "failed to work out behavioral diversity"
+			event.Err = err
+		}
+		ex.log(event)
+	}
+
+	// if we have a best candidate now save it
+	if epoch.Best != nil {
+		//bestOrgPath := fmt.Sprintf("best_%v_%04d", epoch.TrialId, epoch.Id)
+		bestOrgPath := "best"
+		org := epoch.Best
+		event := Event{
+			Level:      LogVerbose,
+			Message:    "saved best genome to " + bestOrgPath,
+			Trial:      epoch.TrialId,
+			Generation: epoch.Id,
+			Genome:     org.Genotype.Id,
+			Fitness:    org.Fitness,
+			Nodes:      len(org.Genotype.Nodes),
+			Genes:      org.Genotype.Extrons(),
+		}
+		file, err := os.Create(bestOrgPath)
+		if err != nil {
+			event.Level = LogProgress
+			event.Message = "failed to save best genome"
+			event.Err = err
+		} else {
+			_, _ = fmt.Fprintf(file, "/* Organism #%d Fitness: %.3f Error: %.3f */\n",
+				org.Genotype.Id, org.Fitness, org.Error)
+			_ = org.Genotype.Write(file)
+			_ = file.Close()
+		}
+		ex.log(event)
+
+		if err := ex.export(org.Genotype, bestOrgPath); err != nil {
+			event.Level = LogProgress
+			event.Message = "failed to export best genome"
+			event.Err = err
+			ex.log(event)
+		}
+	}
+
+	return nil
+}
+
+func (e *PrisonersDilemmaGenerationEvaluator) log(event Event) {
+	if e.Logger == nil {
+		neatLogger{}.Log(event)
+		return
+	}
+	e.Logger.Log(event)
+}
+
+// export saves the genome in whichever extra formats are turned on, next to
+// path with their own extension
+func (e *PrisonersDilemmaGenerationEvaluator) export(g *genetics.Genome, path string) error {
+	if !e.ExportJSON && !e.ExportTable {
+		return nil
+	}
+	j, err := NewGenomeJSON(g, e.Sensors)
+	if err != nil {
+		return err
+	}
+
+	if e.ExportJSON {
+		file, err := os.Create(path + ".json")
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(file)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(j); err != nil {
+			_ = file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	if e.ExportTable {
+		return npz.Write(path+".npz", map[string]interface{}{"table": j.MemoryOne[:]})
+	}
+	return nil
+}
+
+// cachedEvaluate is orgEvaluate but reusing the fitness of an identical
+// genome if one is already in the cache, cache may be nil
+func (e *PrisonersDilemmaGenerationEvaluator) cachedEvaluate(organism *genetics.Organism, cache *fitnessCache) (bool, error) {
+	if cache == nil {
+		return e.orgEvaluate(organism)
+	}
+
+	key := GenomeFingerprint(organism.Genotype)
+	if cached, ok := cache.results[key]; ok {
+		cache.hits++
+		organism.Fitness = cached.fitness
+		organism.Error = 0.0
+		organism.IsWinner = cached.isWinner
+		return organism.IsWinner, nil
+	}
+
+	res, err := e.orgEvaluate(organism)
+	if err != nil {
+		return false, err
+	}
+	cache.results[key] = cachedFitness{fitness: organism.Fitness, isWinner: organism.IsWinner}
+	return res, nil
+}
+
+// opponents is every bot an organism plays against
+func (e *PrisonersDilemmaGenerationEvaluator) opponents() []Opponent {
+	opponents := e.Opponents
+	if len(opponents) == 0 {
+		opponents = []Opponent{{Bot: CooperateBot{}}}
+	}
+	if e.Endgame > 0 {
+		opponents = append(opponents[:len(opponents):len(opponents)], Opponent{Bot: BackwardInductionBot{K: e.Endgame}})
+	}
+	return opponents
+}
+
+func (e *PrisonersDilemmaGenerationEvaluator) orgEvaluate(organism *genetics.Organism) (bool, error) {
+	fitness := 0.0
+	totalWeight := 0.0
+	games := e.games
+	if games <= 0 {
+		games = 1
+	}
+	for _, opponent := range e.opponents() {
+		score := 0.0
+		for i := 0; i < games; i++ {
+			game, err := playOrganism(organism, opponent.Bot, e.GameOptions, e.Sensors)
+			if err != nil {
+				return false, err
+			}
+
+			points := float64(game.AScore)
+			if e.Normalize {
+				points /= float64(game.Rounds)
+			}
+			score += points / float64(games)
+		}
+		fitness += opponent.weight() * score
+		totalWeight += opponent.weight()
+	}
+
+	if totalWeight <= 0 {
+		return false, fmt.Errorf("%w: opponent weights add up to %v", ErrNoOpponents, totalWeight)
+	}
+
+	threshold := winnerScore * totalWeight
+	if e.Normalize {
+		fitness /= totalWeight
+		threshold = winnerScore / float64(DefaultRounds)
+	}
+
+	organism.Fitness = fitness
+	organism.Error = 0.0
+	organism.IsWinner = fitness > threshold
+
+	return organism.IsWinner, nil
+}
+
+// playOrganism plays a game with the organism's network as player A, the
+// opponent sees the same number of rounds as the game so horizon aware bots
+// know when the end is coming
+func playOrganism(organism *genetics.Organism, b Bot, opts GameOptions, sensors SensorSet) (Game, error) {
+	return playNetwork(organism.Phenotype, b, opts, sensors)
+}
+
+// playNetwork plays a game with net as player A the way training does
+func playNetwork(net *network.Network, b Bot, opts GameOptions, sensors SensorSet) (Game, error) {
+	game := NewGame(opts)
+	resetBot(b)
+
+	netDepth, _ := net.MaxActivationDepthFast(0) // The max depth of the network to be activated
+
+	for !game.GameOver() {
+		// get the game state
+		state := game.State()
+
+		// set up our input
+		err := net.LoadSensors(sensors.Values(state.Swap()))
+		if err != nil {
+			return game, err
+		}
+
+		// run the network
+		_, err = net.ForwardSteps(netDepth)
+		if err != nil {
+			return game, err
+		}
+
+		// based on what the network says play!
+		decision := Cooperate
+		if net.Outputs[0].Activation > 0.5 {
+			decision = Defect
+		}
+
+		err = game.Play(gameDecision{
+			aChoice: decision,
+			bChoice: b.Decision(state),
+		})
+		if err != nil {
+			return game, err
+		}
+	}
+
+	game.AwardCooperationBonus()
+	return game, nil
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/yaricom/goNEAT/v2/experiment"
+	"github.com/yaricom/goNEAT/v2/neat"
+	"github.com/yaricom/goNEAT/v2/neat/genetics"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newOrganism builds an organism from a genome in goNEAT's text format
+func newOrganism(t *testing.T, genome string) *genetics.Organism {
+	t.Helper()
+	g, err := genetics.ReadGenome(strings.NewReader(genome), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	organism, err := genetics.NewOrganism(0, g, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return organism
+}
+
+func TestOpponentWeights(t *testing.T) {
+	// always defecting scores 30 against CooperateBot and -10 against
+	// DefectBot
+	alld := memoryOneGenome(0, 0, 10)
+	tests := []struct {
+		name      string
+		cooperate float64
+		defect    float64
+		want      float64
+	}{
+		{"unset", 0, 0, 20},
+		{"equal", 1, 1, 20},
+		{"double DefectBot", 1, 2, 10},
+		{"double CooperateBot", 2, 1, 50},
+		{"half DefectBot", 1, 0.5, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := PrisonersDilemmaGenerationEvaluator{Opponents: []Opponent{
+				{Bot: CooperateBot{}, Weight: tt.cooperate},
+				{Bot: DefectBot{}, Weight: tt.defect},
+			}}
+			organism := newOrganism(t, alld)
+			if _, err := e.orgEvaluate(organism); err != nil {
+				t.Fatal(err)
+			}
+			if organism.Fitness != tt.want {
+				t.Errorf("fitness %v, want %v", organism.Fitness, tt.want)
+			}
+		})
+	}
+
+	e := PrisonersDilemmaGenerationEvaluator{Opponents: []Opponent{
+		{Bot: CooperateBot{}, Weight: 1},
+		{Bot: DefectBot{}, Weight: -1},
+	}}
+	if _, err := e.orgEvaluate(newOrganism(t, alld)); !errors.Is(err, ErrNoOpponents) {
+		t.Errorf("weights adding up to nothing gave %v, want %v", err, ErrNoOpponents)
+	}
+}
+
+// countingBot cooperates and counts how many moves it was asked for
+type countingBot struct {
+	moves int
+}
+
+func (r *countingBot) Decision(state GameState) int {
+	r.moves++
+	return Cooperate
+}
+
+func TestFitnessCache(t *testing.T) {
+	opponent := &countingBot{}
+	e := PrisonersDilemmaGenerationEvaluator{Opponents: []Opponent{{Bot: opponent}}}
+	cache := newFitnessCache()
+
+	alld := memoryOneGenome(0, 0, 10)
+	first, second := newOrganism(t, alld), newOrganism(t, alld)
+	other := newOrganism(t, memoryOneGenome(0, 0, -10))
+	for _, organism := range []*genetics.Organism{first, second, other} {
+		if _, err := e.cachedEvaluate(organism, cache); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if cache.hits != 1 {
+		t.Errorf("%d cache hits, want 1", cache.hits)
+	}
+	if want := 2 * DefaultRounds; opponent.moves != want {
+		t.Errorf("opponent played %d moves, want %d", opponent.moves, want)
+	}
+	if first.Fitness != second.Fitness || first.IsWinner != second.IsWinner {
+		t.Errorf("identical genomes got fitness %v and %v", first.Fitness, second.Fitness)
+	}
+	if other.Fitness == first.Fitness {
+		t.Errorf("different genome reused fitness %v", other.Fitness)
+	}
+}
+
+func TestNormalizedFitness(t *testing.T) {
+	// matchups that score the same every round, so doubling the rounds
+	// doubles the raw score
+	tests := []struct {
+		name      string
+		genome    string
+		opponents []Opponent
+		normalize bool
+		scale     float64 // how fitness changes when the rounds double
+	}{
+		{"always defect", memoryOneGenome(0, 0, 10), []Opponent{{Bot: CooperateBot{}}, {Bot: DefectBot{}, Weight: 2}}, true, 1},
+		{"always cooperate", memoryOneGenome(0, 0, -10), []Opponent{{Bot: CooperateBot{}}, {Bot: TitForTatBot{}}, {Bot: DefectBot{}, Weight: 2}}, true, 1},
+		{"tit for tat", memoryOneGenome(0, 20, -10), []Opponent{{Bot: CooperateBot{}}, {Bot: TitForTatBot{}, Weight: 3}}, true, 1},
+		{"raw", memoryOneGenome(0, 0, 10), []Opponent{{Bot: CooperateBot{}}, {Bot: DefectBot{}, Weight: 2}}, false, 2},
+	}
+	fitness := func(t *testing.T, genome string, opponents []Opponent, normalize bool, rounds int) (float64, bool) {
+		e := PrisonersDilemmaGenerationEvaluator{
+			GameOptions: GameOptions{Rounds: rounds},
+			Opponents:   opponents,
+			Normalize:   normalize,
+		}
+		organism := newOrganism(t, genome)
+		if _, err := e.orgEvaluate(organism); err != nil {
+			t.Fatal(err)
+		}
+		return organism.Fitness, organism.IsWinner
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			short, shortWinner := fitness(t, tt.genome, tt.opponents, tt.normalize, 20)
+			long, longWinner := fitness(t, tt.genome, tt.opponents, tt.normalize, 40)
+			if math.Abs(long-tt.scale*short) > 1e-9 {
+				t.Errorf("fitness %v over 20 rounds and %v over 40", short, long)
+			}
+			if tt.normalize && shortWinner != longWinner {
+				t.Errorf("winner %v over 20 rounds and %v over 40", shortWinner, longWinner)
+			}
+		})
+	}
+}
+
+func TestEvaluatorEndgame(t *testing.T) {
+	allc := memoryOneGenome(0, 0, -10)
+	tests := []struct {
+		rounds int
+		want   float64 // always cooperating against CooperateBot and the endgame bot
+	}{
+		{0, 10 + 7 - 6},
+		{20, 20 + 17 - 6},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d rounds", tt.rounds), func(t *testing.T) {
+			e := PrisonersDilemmaGenerationEvaluator{GameOptions: GameOptions{Rounds: tt.rounds}, Endgame: 3}
+			opponents := e.opponents()
+			if len(opponents) != 2 {
+				t.Fatalf("%d opponents, want CooperateBot and the endgame bot", len(opponents))
+			}
+
+			game, err := playOrganism(newOrganism(t, allc), opponents[1].Bot, e.GameOptions, e.Sensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rounds := tt.rounds
+			if rounds == 0 {
+				rounds = DefaultRounds
+			}
+			if want := strings.Repeat("C", rounds-3) + "DDD"; movesString(game.BHistory) != want {
+				t.Errorf("endgame bot played %s, want %s", movesString(game.BHistory), want)
+			}
+
+			organism := newOrganism(t, allc)
+			if _, err := e.orgEvaluate(organism); err != nil {
+				t.Fatal(err)
+			}
+			if organism.Fitness != tt.want {
+				t.Errorf("fitness %v, want %v", organism.Fitness, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluatorExport(t *testing.T) {
+	tests := []struct {
+		name              string
+		exportJSON, table bool
+	}{
+		{"neither", false, false},
+		{"JSON", true, false},
+		{"table", false, true},
+		{"both", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genome := newOrganism(t, championGenome).Genotype
+			want, err := NewGenomeJSON(genome, DefaultSensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "best")
+			e := PrisonersDilemmaGenerationEvaluator{ExportJSON: tt.exportJSON, ExportTable: tt.table}
+			if err := e.export(genome, path); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path + ".json")
+			if tt.exportJSON != (err == nil) {
+				t.Fatalf("ExportJSON %v but reading %s.json gave %v", tt.exportJSON, path, err)
+			}
+			if tt.exportJSON {
+				var j GenomeJSON
+				if err := json.Unmarshal(data, &j); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(j, want) {
+					t.Errorf("saved\n%+v\nwant\n%+v", j, want)
+				}
+				// and it loads back as a bot the leaderboard can play
+				if _, err := loadGenomeBot(path + ".json"); err != nil {
+					t.Error(err)
+				}
+			}
+
+			bot, err := LoadTableBot(path+".npz", "table")
+			if tt.table != (err == nil) {
+				t.Fatalf("ExportTable %v but loading %s.npz gave %v", tt.table, path, err)
+			}
+			if tt.table && !reflect.DeepEqual(bot.Table, want.MemoryOne[:]) {
+				t.Errorf("saved table %v, want %v", bot.Table, want.MemoryOne)
+			}
+		})
+	}
+
+	// the table is worked out with the evaluator's sensors, and one sensing
+	// the history has no memory one table to export
+	e := PrisonersDilemmaGenerationEvaluator{ExportTable: true, Sensors: SensePreviousMoves | SenseHistory}
+	if err := e.export(newOrganism(t, championGenome).Genotype, filepath.Join(t.TempDir(), "best")); err == nil {
+		t.Error("exported a memory one table for a network sensing its history")
+	}
+}
+
+func TestGenerationEvaluateCancel(t *testing.T) {
+	// a generation that gets to the end saves the best genome here
+	chdirTemp(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// the opponent cancels during the first organism's game, which still
+	// finishes and is scored before the generation stops
+	e := PrisonersDilemmaGenerationEvaluator{
+		Opponents: []Opponent{{Bot: &cancellingBot{After: 3, cancel: cancel}}},
+		Context:   ctx,
+	}
+	alld := memoryOneGenome(0, 0, 10)
+	pop := &genetics.Population{Organisms: []*genetics.Organism{newOrganism(t, alld), newOrganism(t, alld)}}
+	err := e.GenerationEvaluate(pop, &experiment.Generation{Id: 1}, &neat.Options{PopSize: 2})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if got := pop.Organisms[0].Fitness; got != float64(DefaultRounds*DefaultPayoff.Temptation) {
+		t.Errorf("first organism's fitness %v, want %v", got, DefaultRounds*DefaultPayoff.Temptation)
+	}
+	if got := pop.Organisms[1].Fitness; got != 0 {
+		t.Errorf("second organism was evaluated to %v after cancelling", got)
+	}
+}
+
+func TestEvaluatorBudget(t *testing.T) {
+	// a generation saves the best genome to the working directory
+	chdirTemp(t)
+
+	const games, organisms = 5, 4
+	tests := []struct {
+		name    string
+		budget  time.Duration
+		reduced bool
+	}{
+		{"no budget", 0, false},
+		{"plenty", time.Hour, false},
+		// used up before the first organism is done, if not before it starts
+		{"tiny", time.Nanosecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opponent := &countingBot{}
+			var buf bytes.Buffer
+			e := PrisonersDilemmaGenerationEvaluator{
+				Opponents: []Opponent{{Bot: opponent}},
+				Games:     games,
+				Budget:    tt.budget,
+				Logger:    NewLogger(&buf, LogProgress),
+			}
+			pop := &genetics.Population{}
+			for i := 0; i < organisms; i++ {
+				pop.Organisms = append(pop.Organisms, newOrganism(t, memoryOneGenome(0, 0, 10)))
+			}
+			if err := e.GenerationEvaluate(pop, &experiment.Generation{Id: 1}, &neat.Options{PopSize: organisms}); err != nil {
+				t.Fatal(err)
+			}
+
+			full := organisms * games * DefaultRounds
+			if tt.reduced {
+				if most := (games + organisms - 1) * DefaultRounds; opponent.moves > most {
+					t.Errorf("played %d moves, want at most %d once the budget ran out", opponent.moves, most)
+				}
+				if !strings.Contains(buf.String(), "budget") {
+					t.Errorf("no warning logged:\n%s", buf.String())
+				}
+			} else if opponent.moves != full {
+				t.Errorf("played %d moves, want %d", opponent.moves, full)
+			}
+
+			// always defecting against a cooperator, however many games
+			for i, organism := range pop.Organisms {
+				if want := float64(DefaultRounds * DefaultPayoff.Temptation); organism.Fitness != want {
+					t.Errorf("organism %d has fitness %v, want %v", i, organism.Fitness, want)
+				}
+			}
+		})
+	}
+}
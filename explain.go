@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Explain plays one traced game between the named strategies, writes out
+// what happened each round and returns a one line summary of who won and why
+func Explain(w io.Writer, a, b string) (string, error) {
+	aBot, err := NewBot(a)
+	if err != nil {
+		return "", err
+	}
+	bBot, err := NewBot(b)
+	if err != nil {
+		return "", err
+	}
+
+	game, turns := PlayTraced(aBot, bBot, GameOptions{})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintf(tw, "round\t%s\t%s\tpoints\tscore\t\n", a, b)
+	for _, t := range turns {
+		_, _ = fmt.Fprintf(tw, "%d\t%c\t%c\t%d:%d\t%d:%d\t\n",
+			t.Round, moveLetter(t.A), moveLetter(t.B), t.APoints, t.BPoints, t.AScore, t.BScore)
+	}
+	_ = tw.Flush()
+
+	summary := explainTurns(a, b, game, turns)
+	_, _ = fmt.Fprintln(w, summary)
+
+	return summary, nil
+}
+
+func explainTurns(a, b string, game Game, turns []Turn) string {
+	var result string
+	switch {
+	case game.AScore > game.BScore:
+		result = fmt.Sprintf("%s beat %s %d to %d", a, b, game.AScore, game.BScore)
+	case game.AScore < game.BScore:
+		result = fmt.Sprintf("%s beat %s %d to %d", b, a, game.BScore, game.AScore)
+	default:
+		result = fmt.Sprintf("%s and %s drew on %d", a, b, game.AScore)
+	}
+
+	for i, t := range turns {
+		if t.A != Defect && t.B != Defect {
+			continue
+		}
+
+		if t.A == Defect && t.B == Defect {
+			return fmt.Sprintf("%s, both defected together on round %d", result, t.Round)
+		}
+
+		defector, victim := a, b
+		retaliated := func(t Turn) bool { return t.B == Defect }
+		if t.B == Defect {
+			defector, victim = b, a
+			retaliated = func(t Turn) bool { return t.A == Defect }
+		}
+
+		if i+1 < len(turns) && retaliated(turns[i+1]) {
+			return fmt.Sprintf("%s, %s retaliated after %s defected on round %d", result, victim, defector, t.Round)
+		}
+		for _, later := range turns[i+1:] {
+			if retaliated(later) {
+				return fmt.Sprintf("%s, %s defected first on round %d and %s hit back on round %d", result, defector, t.Round, victim, later.Round)
+			}
+		}
+		return fmt.Sprintf("%s, %s defected first on round %d and %s never retaliated", result, defector, t.Round, victim)
+	}
+
+	return fmt.Sprintf("%s, both cooperated every round", result)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"TitForTatBot", "DefectBot", "DefectBot beat TitForTatBot -6 to -11, TitForTatBot retaliated after DefectBot defected on round 1"},
+		{"CooperateBot", "DefectBot", "DefectBot beat CooperateBot 30 to -20, DefectBot defected first on round 1 and CooperateBot never retaliated"},
+		{"CooperateBot", "CooperateBot", "CooperateBot and CooperateBot drew on 10, both cooperated every round"},
+		{"DefectBot", "DefectBot", "DefectBot and DefectBot drew on -10, both defected together on round 1"},
+		{"BackwardInductionBot", "TitForTatBot", "BackwardInductionBot beat TitForTatBot 10 to 5, TitForTatBot retaliated after BackwardInductionBot defected on round 9"},
+		{"SlowTitForTatBot", "DefectBot", "DefectBot beat SlowTitForTatBot -2 to -12, DefectBot defected first on round 1 and SlowTitForTatBot hit back on round 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" against "+tt.b, func(t *testing.T) {
+			var buf bytes.Buffer
+			got, err := Explain(&buf, tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("summary\n%s\nwant\n%s", got, tt.want)
+			}
+			// a header, a line per round and the summary
+			if lines := strings.Count(buf.String(), "\n"); lines != DefaultRounds+2 {
+				t.Errorf("wrote %d lines, want %d", lines, DefaultRounds+2)
+			}
+		})
+	}
+
+	if _, err := Explain(&bytes.Buffer{}, "TitForTatBot", "NoSuchBot"); err == nil {
+		t.Error("explaining an unknown strategy should fail")
+	}
+}
@@ -1,16 +1,91 @@
 package main
 
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrInvalidMove is returned when a move is neither Cooperate nor Defect
+	ErrInvalidMove = errors.New("invalid move")
+	// ErrGameOver is returned when a round is played after the last one
+	ErrGameOver = errors.New("game is over")
+	// ErrNoOpponents is returned when there is nobody to play against
+	ErrNoOpponents = errors.New("no opponents")
+)
+
 const (
 	Cooperate = iota
 	Defect
 )
 
+// NoMove is played by both sides to clear the previous moves before the
+// first real round, it scores nothing, doesn't count as a round and is not
+// kept in the history
+const NoMove = -1
+
+// DefaultRounds is how many rounds a game lasts unless configured otherwise
+const DefaultRounds = 10
+
 type Game struct {
 	AScore    int
 	BScore    int
 	Round     int
+	Rounds    int
 	APrevious int
 	BPrevious int
+	AHistory  []int
+	BHistory  []int
+	Payoff    Payoff
+	BPayoff   *Payoff // what B scores from if it differs from A, Payoff if nil
+
+	// WarmupRounds are the first rounds of the game, which are played as
+	// normal but scored into AWarmup and BWarmup instead of the scores
+	WarmupRounds int
+	AWarmup      int
+	BWarmup      int
+
+	// ScoreBounds if set keeps AScore and BScore within it, scores always
+	// stop at the most and least an int can hold rather than overflowing
+	ScoreBounds *ScoreBounds
+
+	// CooperationBonus is what both sides get at the end of the game for
+	// every scored round they both cooperated, see AwardCooperationBonus
+	CooperationBonus int
+}
+
+// ScoreBounds is the least and most a side's score is allowed to be
+type ScoreBounds struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// GameOptions configures a game, the zero value is the default game
+type GameOptions struct {
+	Rounds  int     // DefaultRounds if unset
+	Payoff  *Payoff // DefaultPayoff if unset
+	BPayoff *Payoff // gives B a different payoff to A if set
+
+	// EarlyStop ends a game once the same pair of moves has been played
+	// three rounds running and whoever is ahead can no longer be caught, the
+	// rest of the game is scored as if that pair kept being played. That is
+	// only what would have happened for bots that react to the last few
+	// moves and not to how many rounds are left, so it only applies when
+	// both bots are Stationary
+	EarlyStop bool
+
+	// WarmupRounds leaves the first rounds out of the scores so only how
+	// the bots play once they have settled down counts
+	WarmupRounds int
+
+	// ScoreBounds clamps the scores, for huge games or payoffs with
+	// negative values where runaway scores make standings hard to read
+	ScoreBounds *ScoreBounds
+
+	// CooperationBonus rewards sustained mutual cooperation on top of the
+	// payoff, to see which strategies win when the incentives change
+	CooperationBonus int
 }
 
 func CreateGame() Game {
@@ -18,15 +93,38 @@ func CreateGame() Game {
 		AScore:    0,
 		BScore:    0,
 		Round:     0,
+		Rounds:    DefaultRounds,
 		APrevious: 0,
 		BPrevious: 0,
+		Payoff:    DefaultPayoff,
 	}
 }
 
+// NewGame creates a game set up with opts
+func NewGame(opts GameOptions) Game {
+	game := CreateGame()
+	if opts.Rounds > 0 {
+		game.Rounds = opts.Rounds
+	}
+	if opts.Payoff != nil {
+		game.Payoff = *opts.Payoff
+	}
+	game.BPayoff = opts.BPayoff
+	game.WarmupRounds = opts.WarmupRounds
+	game.ScoreBounds = opts.ScoreBounds
+	game.CooperationBonus = opts.CooperationBonus
+	return game
+}
+
 type GameState struct {
 	aPrevious int
 	bPrevious int
+	aHistory  []int
+	bHistory  []int
 	round     int
+	rounds    int
+	aPayoff   Payoff
+	bPayoff   Payoff
 }
 
 type gameDecision struct {
@@ -38,46 +136,202 @@ func (g *Game) State() GameState {
 	return GameState{
 		aPrevious: g.APrevious,
 		bPrevious: g.BPrevious,
+		aHistory:  g.AHistory,
+		bHistory:  g.BHistory,
 		round:     g.Round,
+		rounds:    g.Rounds,
+		aPayoff:   g.Payoff,
+		bPayoff:   g.bPayoff(),
+	}
+}
+
+// bPayoff is the payoff B scores from
+func (g *Game) bPayoff() Payoff {
+	if g.BPayoff != nil {
+		return *g.BPayoff
 	}
+	return g.Payoff
+}
+
+// Swap returns the state as seen from the other seat. Bots are handed the
+// state with their opponent in the a fields, so whoever plays as player A
+// needs the swapped state
+func (s GameState) Swap() GameState {
+	s.aPrevious, s.bPrevious = s.bPrevious, s.aPrevious
+	s.aHistory, s.bHistory = s.bHistory, s.aHistory
+	s.aPayoff, s.bPayoff = s.bPayoff, s.aPayoff
+	return s
+}
+
+// RoundsLeft is how many rounds are left to play, counting the one about to
+// be played
+func (s GameState) RoundsLeft() int {
+	return s.rounds - s.round
+}
+
+// Swap returns the game with the players' seats swapped, so B's score,
+// moves and payoff become A's and the other way round
+func (g Game) Swap() Game {
+	g.AScore, g.BScore = g.BScore, g.AScore
+	g.APrevious, g.BPrevious = g.BPrevious, g.APrevious
+	g.AHistory, g.BHistory = g.BHistory, g.AHistory
+	g.AWarmup, g.BWarmup = g.BWarmup, g.AWarmup
+	if g.BPayoff != nil {
+		payoff := g.Payoff
+		g.Payoff, g.BPayoff = *g.BPayoff, &payoff
+	}
+	return g
 }
 
 func (g *Game) GameOver() bool {
-	if g.Round > 10 {
+	if g.Round >= g.Rounds {
 		return true
 	}
 
 	return false
 }
 
-func (g *Game) Play(d gameDecision) {
-	// if both play nice then both get a small reward
-	if d.aChoice == Cooperate && d.bChoice == Cooperate {
-		g.AScore += 1
-		g.BScore += 1
+// settled is true when the last three rounds were the same pair of moves and
+// the leader would stay ahead whatever happened in the rounds left
+func (g *Game) settled() bool {
+	n := len(g.AHistory)
+	// a clamped score can stop the leader pulling away, so who can still be
+	// caught isn't known
+	if n < 3 || g.GameOver() || g.ScoreBounds != nil {
+		return false
+	}
+	for i := n - 3; i < n-1; i++ {
+		if g.AHistory[i] != g.APrevious || g.BHistory[i] != g.BPrevious {
+			return false
+		}
+	}
+
+	// the most and least A's lead can change by in a round
+	most, least := math.MinInt, math.MaxInt
+	for _, a := range []int{Cooperate, Defect} {
+		for _, b := range []int{Cooperate, Defect} {
+			aScore, _ := g.Payoff.Score(a, b)
+			_, bScore := g.bPayoff().Score(a, b)
+			if aScore-bScore > most {
+				most = aScore - bScore
+			}
+			if aScore-bScore < least {
+				least = aScore - bScore
+			}
+		}
+	}
+
+	lead := g.AScore - g.BScore
+	left := g.Rounds - g.Round
+	if g.Round < g.WarmupRounds {
+		left = g.Rounds - g.WarmupRounds
 	}
+	return lead+left*least > 0 || lead+left*most < 0
+}
 
-	// if both defect then nothing
-	if d.aChoice == Defect && d.bChoice == Defect {
-		g.AScore--
-		g.BScore--
+// project plays out the rest of the game as the last round over again
+func (g *Game) project() {
+	for !g.GameOver() {
+		_ = g.Play(gameDecision{
+			aChoice: g.APrevious,
+			bChoice: g.BPrevious,
+		})
 	}
+}
 
-	// if you cooperate and they don't you get a punishment
-	// and they get a reward
-	if d.aChoice == Cooperate && d.bChoice == Defect {
-		g.BScore += 3
-		g.AScore -= 2
+// Play plays a round, unless both sides play NoMove to start the game. A
+// move that isn't Cooperate or Defect is ErrInvalidMove and playing past the
+// last round is ErrGameOver, either way the game is left as it was
+func (g *Game) Play(d gameDecision) error {
+	priming := d.aChoice == NoMove && d.bChoice == NoMove
+	if !priming {
+		if !validMove(d.aChoice) || !validMove(d.bChoice) {
+			return fmt.Errorf("%w: %d and %d", ErrInvalidMove, d.aChoice, d.bChoice)
+		}
+		if g.GameOver() {
+			return ErrGameOver
+		}
 	}
-	if d.aChoice == Defect && d.bChoice == Cooperate {
-		g.AScore += 3
-		g.BScore -= 2
+
+	// both play nice and both get a small reward, both defect and both lose
+	// out, and if one cooperates while the other defects the defector is
+	// rewarded and the cooperator punished. Each side scores from its own
+	// payoff, which are the same unless B was given a different one
+	aScore, _ := g.Payoff.Score(d.aChoice, d.bChoice)
+	_, bScore := g.bPayoff().Score(d.aChoice, d.bChoice)
+	if !priming && g.Round < g.WarmupRounds {
+		g.AWarmup = addScore(g.AWarmup, aScore)
+		g.BWarmup = addScore(g.BWarmup, bScore)
+	} else {
+		g.AScore = g.clamp(addScore(g.AScore, aScore))
+		g.BScore = g.clamp(addScore(g.BScore, bScore))
 	}
 
 	// keep what happened last round so we can feed that back
 	g.APrevious = d.aChoice
 	g.BPrevious = d.bChoice
+	if priming {
+		return nil
+	}
+	g.AHistory = append(g.AHistory, d.aChoice)
+	g.BHistory = append(g.BHistory, d.bChoice)
 
 	// increment the round
 	g.Round++
+	return nil
+}
+
+// addScore adds points to score, stopping at the most or least an int can
+// hold instead of wrapping around
+func addScore(score, points int) int {
+	if points > 0 && score > math.MaxInt-points {
+		return math.MaxInt
+	}
+	if points < 0 && score < math.MinInt-points {
+		return math.MinInt
+	}
+	return score + points
+}
+
+// clamp keeps score within the game's ScoreBounds if it has any
+func (g *Game) clamp(score int) int {
+	if g.ScoreBounds == nil {
+		return score
+	}
+	if score < g.ScoreBounds.Min {
+		return g.ScoreBounds.Min
+	}
+	if score > g.ScoreBounds.Max {
+		return g.ScoreBounds.Max
+	}
+	return score
+}
+
+// MutualCooperations is how many of the scored rounds both sides cooperated
+// in, warmup rounds don't count
+func (g *Game) MutualCooperations() int {
+	count := 0
+	for i := g.WarmupRounds; i < len(g.AHistory); i++ {
+		if g.AHistory[i] == Cooperate && g.BHistory[i] == Cooperate {
+			count++
+		}
+	}
+	return count
+}
+
+// AwardCooperationBonus adds CooperationBonus for every mutual cooperation
+// to both scores. It is worked out from the history once the game is over
+// so Play always scores exactly what the payoff says, and it is up to
+// whoever plays the game to call it once at the end
+func (g *Game) AwardCooperationBonus() {
+	if g.CooperationBonus == 0 {
+		return
+	}
+	bonus := g.CooperationBonus * g.MutualCooperations()
+	g.AScore = g.clamp(addScore(g.AScore, bonus))
+	g.BScore = g.clamp(addScore(g.BScore, bonus))
+}
+
+func validMove(move int) bool {
+	return move == Cooperate || move == Defect
 }
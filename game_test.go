@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGameStateSwap(t *testing.T) {
+	game := CreateGame()
+	game.Play(gameDecision{aChoice: Cooperate, bChoice: Defect})
+
+	state := game.State()
+	swapped := state.Swap()
+	// from A's seat B is the opponent, so B's move is in the a fields
+	if swapped.aPrevious != Defect || swapped.bPrevious != Cooperate {
+		t.Fatalf("swapped state has a %d and b %d, want a %d and b %d",
+			swapped.aPrevious, swapped.bPrevious, Defect, Cooperate)
+	}
+	if swapped.round != state.round {
+		t.Errorf("swapped state is round %d, want %d", swapped.round, state.round)
+	}
+	if !reflect.DeepEqual(swapped.Swap(), state) {
+		t.Errorf("swapping twice gave %+v, want %+v", swapped.Swap(), state)
+	}
+
+	// sitting as A, mirror copies B's move from its own seat
+	if got := (MirrorBot{}).Decision(swapped); got != Defect {
+		t.Errorf("mirror as player A played %d, want %d", got, Defect)
+	}
+}
+
+// playRounds plays a game of the given length the way the tournament does
+func playRounds(a, b Bot, rounds int) Game {
+	game := NewGame(GameOptions{Rounds: rounds})
+	resetBot(a)
+	resetBot(b)
+	game.Play(gameDecision{aChoice: NoMove, bChoice: NoMove})
+	for i := 0; i < rounds; i++ {
+		state := game.State()
+		game.Play(gameDecision{
+			aChoice: a.Decision(state.Swap()),
+			bChoice: b.Decision(state),
+		})
+	}
+	return game
+}
+
+func TestGameRounds(t *testing.T) {
+	tests := []struct {
+		name string
+		opts GameOptions
+		want int
+	}{
+		// the NoMove round that starts the game doesn't count
+		{"default", GameOptions{}, 10},
+		{"one", GameOptions{Rounds: 1}, 1},
+		{"twenty", GameOptions{Rounds: 20}, 20},
+		{"negative", GameOptions{Rounds: -5}, DefaultRounds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(CooperateBot{}, TitForTatBot{}, tt.opts)
+			if game.Round != tt.want || game.Rounds != tt.want {
+				t.Errorf("played %d of %d rounds, want %d", game.Round, game.Rounds, tt.want)
+			}
+			if len(game.AHistory) != tt.want || len(game.BHistory) != tt.want {
+				t.Errorf("kept %d and %d moves, want %d", len(game.AHistory), len(game.BHistory), tt.want)
+			}
+			// every round is mutual cooperation, worth the reward
+			if game.AScore != tt.want*DefaultPayoff.Reward {
+				t.Errorf("scored %d, want %d", game.AScore, tt.want*DefaultPayoff.Reward)
+			}
+		})
+	}
+}
+
+func TestAsymmetricPayoff(t *testing.T) {
+	greedy := DefaultPayoff
+	greedy.Temptation = 5
+	tests := []struct {
+		name           string
+		a, b           Bot
+		aPayoff        *Payoff
+		bPayoff        *Payoff
+		aScore, bScore int
+	}{
+		{"A exploits B", DefectBot{}, CooperateBot{}, &greedy, nil, 50, -20},
+		{"B exploits A", CooperateBot{}, DefectBot{}, &greedy, &DefaultPayoff, -20, 30},
+		{"B is the greedy one", CooperateBot{}, DefectBot{}, nil, &greedy, -20, 50},
+		{"both defect", DefectBot{}, DefectBot{}, &greedy, &DefaultPayoff, -10, -10},
+		{"symmetric", DefectBot{}, CooperateBot{}, nil, nil, 30, -20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(tt.a, tt.b, GameOptions{Payoff: tt.aPayoff, BPayoff: tt.bPayoff})
+			if game.AScore != tt.aScore || game.BScore != tt.bScore {
+				t.Errorf("scored %d to %d, want %d to %d", game.AScore, game.BScore, tt.aScore, tt.bScore)
+			}
+
+			// swapping seats takes each side's payoff with it
+			swapped := game.Swap()
+			if swapped.Payoff != game.bPayoff() || swapped.bPayoff() != game.Payoff {
+				t.Errorf("swapped payoffs %+v and %+v", swapped.Payoff, swapped.bPayoff())
+			}
+		})
+	}
+
+	// B sees its own payoff in the b fields, A in the a fields of its
+	// swapped state
+	game := NewGame(GameOptions{BPayoff: &greedy})
+	if state := game.State(); state.aPayoff != DefaultPayoff || state.bPayoff != greedy {
+		t.Errorf("B sees its payoff as %+v and A's as %+v", state.bPayoff, state.aPayoff)
+	}
+	if state := game.State().Swap(); state.aPayoff != greedy || state.bPayoff != DefaultPayoff {
+		t.Errorf("A sees its payoff as %+v and B's as %+v", state.bPayoff, state.aPayoff)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	broken := func() Bot { return &ScriptedBot{Moves: []int{Cooperate, 7}} }
+	finished := NewGame(GameOptions{Rounds: 1})
+	_ = finished.Play(gameDecision{aChoice: Cooperate, bChoice: Cooperate})
+
+	tests := []struct {
+		name string
+		err  func() error
+		want error
+	}{
+		{"invalid move", func() error {
+			game := NewGame(GameOptions{})
+			return game.Play(gameDecision{aChoice: Cooperate, bChoice: 7})
+		}, ErrInvalidMove},
+		{"one side priming", func() error {
+			game := NewGame(GameOptions{})
+			return game.Play(gameDecision{aChoice: NoMove, bChoice: Defect})
+		}, ErrInvalidMove},
+		{"game over", func() error {
+			return finished.Play(gameDecision{aChoice: Cooperate, bChoice: Cooperate})
+		}, ErrGameOver},
+		{"bot plays an invalid move", func() error {
+			_, err := RunTournament(context.Background(), map[string]Bot{"broken": broken(), "TitForTatBot": TitForTatBot{}}, TournamentOptions{Games: 1})
+			return err
+		}, ErrInvalidMove},
+		{"tournament without bots", func() error {
+			_, err := RunTournament(context.Background(), nil, TournamentOptions{})
+			return err
+		}, ErrNoOpponents},
+		{"cross tournament without columns", func() error {
+			_, err := RunCrossTournament(context.Background(), map[string]Bot{"TitForTatBot": TitForTatBot{}}, nil, TournamentOptions{})
+			return err
+		}, ErrNoOpponents},
+		{"evaluator without opponent weight", func() error {
+			e := PrisonersDilemmaGenerationEvaluator{Opponents: []Opponent{{Bot: CooperateBot{}, Weight: -1}}}
+			_, err := e.orgEvaluate(newOrganism(t, memoryOneGenome(0, 0, 10)))
+			return err
+		}, ErrNoOpponents},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.err(); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// a rejected round leaves the game as it was
+	game := NewGame(GameOptions{})
+	_ = game.Play(gameDecision{aChoice: Defect, bChoice: Cooperate})
+	before := game
+	_ = game.Play(gameDecision{aChoice: 7, bChoice: Cooperate})
+	if game.Round != before.Round || game.AScore != before.AScore || len(game.AHistory) != len(before.AHistory) {
+		t.Errorf("invalid move changed the game from %+v to %+v", before, game)
+	}
+}
+
+func TestConstantMoveUnchanged(t *testing.T) {
+	pairs := []struct {
+		name string
+		a, b func() Bot
+	}{
+		{"DefectBot against CooperateBot", func() Bot { return DefectBot{} }, func() Bot { return CooperateBot{} }},
+		{"CooperateBot against TitForTatBot", func() Bot { return CooperateBot{} }, func() Bot { return TitForTatBot{} }},
+		{"ThresholdGrimBot against DefectBot", func() Bot { return ThresholdGrimBot{K: 1} }, func() Bot { return DefectBot{} }},
+		{"DefectBot against Alternator", func() Bot { return DefectBot{} }, func() Bot { return &ScriptedBot{Moves: moves("CD"), Loop: true} }},
+	}
+	options := []struct {
+		name string
+		opts GameOptions
+	}{
+		{"default", GameOptions{}},
+		{"warmup and bonus", GameOptions{Rounds: 30, WarmupRounds: 4, CooperationBonus: 2}},
+		{"bounded", GameOptions{ScoreBounds: &ScoreBounds{Min: -5, Max: 5}}},
+	}
+	for _, pair := range pairs {
+		for _, o := range options {
+			t.Run(pair.name+"/"+o.name, func(t *testing.T) {
+				fast := PlayGame(pair.a(), pair.b(), o.opts)
+				// dispatchBot hides the constant move so every round asks,
+				// it hides Reset too so each game needs fresh bots
+				slow := PlayGame(dispatchBot{pair.a()}, dispatchBot{pair.b()}, o.opts)
+				if !reflect.DeepEqual(fast, slow) {
+					t.Errorf("skipping decisions gave\n%+v\nasking every round gave\n%+v", fast, slow)
+				}
+			})
+		}
+	}
+}
+
+func TestWarmupRounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		warmup int
+	}{
+		{"none", 0},
+		{"three", 3},
+		{"whole game", DefaultRounds},
+		{"longer than the game", DefaultRounds + 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(scripted("DDCDCCDCDDC"), TitForTatBot{}, GameOptions{WarmupRounds: tt.warmup})
+			full := PlayGame(scripted("DDCDCCDCDDC"), TitForTatBot{}, GameOptions{})
+			if !reflect.DeepEqual(game.AHistory, full.AHistory) || !reflect.DeepEqual(game.BHistory, full.BHistory) {
+				t.Errorf("warming up changed the moves from %s %s to %s %s", movesString(full.AHistory), movesString(full.BHistory), movesString(game.AHistory), movesString(game.BHistory))
+			}
+
+			var a, b, aWarmup, bWarmup int
+			for i := range game.AHistory {
+				aPoints, bPoints := DefaultPayoff.Score(game.AHistory[i], game.BHistory[i])
+				if i < tt.warmup {
+					aWarmup, bWarmup = aWarmup+aPoints, bWarmup+bPoints
+				} else {
+					a, b = a+aPoints, b+bPoints
+				}
+			}
+			if game.AScore != a || game.BScore != b {
+				t.Errorf("scored %d to %d, want %d to %d", game.AScore, game.BScore, a, b)
+			}
+			if game.AWarmup != aWarmup || game.BWarmup != bWarmup {
+				t.Errorf("warmup scored %d to %d, want %d to %d", game.AWarmup, game.BWarmup, aWarmup, bWarmup)
+			}
+		})
+	}
+}
+
+func TestScoreBounds(t *testing.T) {
+	// punishments big enough that a handful of them would wrap an int
+	huge := Payoff{Temptation: 3, Reward: 1, Punishment: math.MinInt / 4, Sucker: math.MinInt / 2}
+	tests := []struct {
+		name string
+		opts GameOptions
+		want int
+	}{
+		{"unbounded", GameOptions{Rounds: 100000}, 100000 * DefaultPayoff.Punishment},
+		{"bounded", GameOptions{Rounds: 100000, ScoreBounds: &ScoreBounds{Min: -1000, Max: 1000}}, -1000},
+		{"within bounds", GameOptions{Rounds: 500, ScoreBounds: &ScoreBounds{Min: -1000, Max: 1000}}, 500 * DefaultPayoff.Punishment},
+		{"saturates instead of overflowing", GameOptions{Rounds: 10, Payoff: &huge}, math.MinInt},
+		{"saturated then bounded", GameOptions{Rounds: 10, Payoff: &huge, ScoreBounds: &ScoreBounds{Min: -1000, Max: 1000}}, -1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(DefectBot{}, DefectBot{}, tt.opts)
+			if game.AScore != tt.want || game.BScore != tt.want {
+				t.Errorf("scored %d to %d, want %d each", game.AScore, game.BScore, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddScore(t *testing.T) {
+	tests := []struct {
+		name          string
+		score, points int
+		want          int
+	}{
+		{"adds", 5, -3, 2},
+		{"up to the most", math.MaxInt - 1, 1, math.MaxInt},
+		{"past the most", math.MaxInt - 1, 5, math.MaxInt},
+		{"down to the least", math.MinInt + 1, -1, math.MinInt},
+		{"past the least", math.MinInt + 1, -5, math.MinInt},
+		{"back from the least", math.MinInt, 5, math.MinInt + 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addScore(tt.score, tt.points); got != tt.want {
+				t.Errorf("addScore(%d, %d) = %d, want %d", tt.score, tt.points, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCooperationBonus(t *testing.T) {
+	tests := []struct {
+		name         string
+		a, b         Bot
+		opts         GameOptions
+		aWant, bWant int
+	}{
+		{"cooperators", CooperateBot{}, CooperateBot{}, GameOptions{CooperationBonus: 2}, 10 + 20, 10 + 20},
+		{"defectors", DefectBot{}, DefectBot{}, GameOptions{CooperationBonus: 2}, -10, -10},
+		{"never both cooperate", DefectBot{}, CooperateBot{}, GameOptions{CooperationBonus: 2}, 30, -20},
+		// they fall out for two rounds and cooperate in the other eight
+		{"some rounds", TitForTatBot{}, scripted("CCDC"), GameOptions{CooperationBonus: 1}, 9 + 8, 9 + 8},
+		{"warmup doesn't count", CooperateBot{}, CooperateBot{}, GameOptions{WarmupRounds: 3, CooperationBonus: 2}, 7 + 14, 7 + 14},
+		{"bounded", CooperateBot{}, CooperateBot{}, GameOptions{CooperationBonus: 2, ScoreBounds: &ScoreBounds{Min: -15, Max: 15}}, 15, 15},
+		{"no bonus", CooperateBot{}, CooperateBot{}, GameOptions{}, 10, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := PlayGame(tt.a, tt.b, tt.opts)
+			if game.AScore != tt.aWant || game.BScore != tt.bWant {
+				t.Errorf("scored %d to %d, want %d to %d", game.AScore, game.BScore, tt.aWant, tt.bWant)
+			}
+		})
+	}
+
+	// Play leaves it out, it only comes in once the game is over
+	game := NewGame(GameOptions{Rounds: 3, CooperationBonus: 5})
+	_ = game.Play(gameDecision{aChoice: NoMove, bChoice: NoMove})
+	for !game.GameOver() {
+		_ = game.Play(gameDecision{aChoice: Cooperate, bChoice: Cooperate})
+	}
+	if game.AScore != 3 {
+		t.Errorf("played for %d before the bonus, want 3", game.AScore)
+	}
+	game.AwardCooperationBonus()
+	if game.AScore != 3+15 {
+		t.Errorf("scored %d with the bonus, want %d", game.AScore, 3+15)
+	}
+}
+
+func TestEarlyStopMatchesFullGame(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   func() Bot
+		rounds int
+	}{
+		{"TitForTatBot against DefectBot", func() Bot { return TitForTatBot{} }, func() Bot { return DefectBot{} }, 200},
+		{"HardTitForTatBot against TitForTatBotReverse", func() Bot { return HardTitForTatBot{} }, func() Bot { return TitForTatBotReverse{} }, 50},
+		// settles three rounds ahead and then changes its mind, which only
+		// playing it out shows
+		{"ScriptedBot against CooperateBot", func() Bot {
+			return &ScriptedBot{Moves: []int{Defect, Defect, Defect, Cooperate}}
+		}, func() Bot { return CooperateBot{} }, 5},
+		// defects in the last rounds however the game went before
+		{"DefectBot against BackwardInductionBot", func() Bot { return DefectBot{} }, func() Bot { return BackwardInductionBot{K: 2} }, 20},
+		{"BackwardInductionBot against ScriptedBot", func() Bot { return BackwardInductionBot{K: 3} }, func() Bot {
+			return &ScriptedBot{Moves: []int{Defect, Defect, Defect, Defect, Cooperate}}
+		}, 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			full := PlayGame(tt.a(), tt.b(), GameOptions{Rounds: tt.rounds})
+			early := PlayGame(tt.a(), tt.b(), GameOptions{Rounds: tt.rounds, EarlyStop: true})
+			if early.AScore != full.AScore || early.BScore != full.BScore {
+				t.Errorf("stopping early scored %d to %d, playing it out scored %d to %d",
+					early.AScore, early.BScore, full.AScore, full.BScore)
+			}
+			if movesString(early.AHistory) != movesString(full.AHistory) || movesString(early.BHistory) != movesString(full.BHistory) {
+				t.Errorf("stopping early played\n%s\n%s\nplaying it out played\n%s\n%s",
+					movesString(early.AHistory), movesString(early.BHistory),
+					movesString(full.AHistory), movesString(full.BHistory))
+			}
+		})
+	}
+}
+
+func TestStationary(t *testing.T) {
+	tests := []struct {
+		bot  Bot
+		want bool
+	}{
+		{TitForTatBot{}, true},
+		{DefectBot{}, true},
+		{CooperateBot{}, true},
+		{HardTitForTatBot{}, true},
+		{BackwardInductionBot{K: 2}, false},
+		{&ScriptedBot{Moves: []int{Cooperate}}, false},
+		{&RandomBot{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%T", tt.bot), func(t *testing.T) {
+			if got := stationary(tt.bot); got != tt.want {
+				t.Errorf("stationary = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
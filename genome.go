@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"github.com/yaricom/goNEAT/v2/neat"
+	"github.com/yaricom/goNEAT/v2/neat/genetics"
+	neatmath "github.com/yaricom/goNEAT/v2/neat/math"
+	"github.com/yaricom/goNEAT/v2/neat/network"
+	"hash/fnv"
+	"io"
+	"sort"
+	"strings"
+)
+
+// genomeKey describes the structure of a genome in a canonical form, so two
+// genomes that would build the same network get the same key however their
+// nodes and genes happen to be ordered
+func genomeKey(g *genetics.Genome) string {
+	nodes := make([]string, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes = append(nodes, fmt.Sprintf("%d:%d:%d", n.Id, n.NeuronType, n.ActivationType))
+	}
+	sort.Strings(nodes)
+
+	links := make([]string, 0, len(g.Genes))
+	for _, gene := range g.Genes {
+		if !gene.IsEnabled {
+			continue
+		}
+		l := gene.Link
+		links = append(links, fmt.Sprintf("%d>%d:%v:%t:%t",
+			l.InNode.Id, l.OutNode.Id, l.ConnectionWeight, l.IsRecurrent, l.IsTimeDelayed))
+	}
+	sort.Strings(links)
+
+	return strings.Join(nodes, ",") + "|" + strings.Join(links, ",")
+}
+
+// GenomeFingerprint hashes the canonical form of the genome, so genomes that
+// would build the same network share a fingerprint however their nodes and
+// genes are ordered while any change to the wiring or a weight gives a
+// different one
+func GenomeFingerprint(g *genetics.Genome) uint64 {
+	h := fnv.New64a()
+	_, _ = io.WriteString(h, genomeKey(g))
+	return h.Sum64()
+}
+
+// BehaviorSignature plays the network against always cooperate, always
+// defect, tit for tat and an alternator in that order and returns every move
+// it made one game after the other. The network is given the inputs in
+// sensors, the set it was trained with. Networks that play the same way get
+// the same signature however differently they are wired
+func BehaviorSignature(net *network.Network, sensors SensorSet) ([]int, error) {
+	probes := []Bot{
+		CooperateBot{},
+		DefectBot{},
+		TitForTatBot{},
+		&ScriptedBot{Moves: []int{Cooperate, Defect}, Loop: true},
+	}
+
+	signature := make([]int, 0, len(probes)*DefaultRounds)
+	for _, probe := range probes {
+		// start each game from a clean network so the order of the probes
+		// doesn't matter
+		if _, err := net.Flush(); err != nil {
+			return nil, err
+		}
+		game, err := playNetwork(net, probe, GameOptions{Rounds: DefaultRounds}, sensors)
+		if err != nil {
+			return nil, fmt.Errorf("playing %s: %w", botType(probe), err)
+		}
+		signature = append(signature, game.AHistory...)
+	}
+	return signature, nil
+}
+
+// GenomeJSON is a genome in plain JSON for tools that can't read goNEAT's
+// own format, along with the memory one table its network plays
+type GenomeJSON struct {
+	ID        int         `json:"id"`
+	Traits    []TraitJSON `json:"traits"`
+	Nodes     []NodeJSON  `json:"nodes"`
+	Genes     []GeneJSON  `json:"genes"`
+	MemoryOne [4]float64  `json:"memory_one"` // as ExtractMemoryOneTable
+}
+
+type TraitJSON struct {
+	ID     int       `json:"id"`
+	Params []float64 `json:"params"`
+}
+
+// NodeJSON is a node, Type is one of goNEAT's neuron type names like INPT
+// and Activation the name of its activation function
+type NodeJSON struct {
+	ID         int    `json:"id"`
+	Type       string `json:"type"`
+	Activation string `json:"activation"`
+	Trait      int    `json:"trait,omitempty"`
+}
+
+// GeneJSON is a link between two nodes by their ids
+type GeneJSON struct {
+	In          int     `json:"in"`
+	Out         int     `json:"out"`
+	Weight      float64 `json:"weight"`
+	Recurrent   bool    `json:"recurrent"`
+	TimeDelayed bool    `json:"time_delayed,omitempty"`
+	Innovation  int64   `json:"innovation"`
+	Mutation    float64 `json:"mutation"`
+	Enabled     bool    `json:"enabled"`
+	Trait       int     `json:"trait,omitempty"`
+}
+
+// NewGenomeJSON converts g, its network is built from the conversion so g
+// and its phenotype are left alone. sensors are the inputs the network was
+// trained with, which its memory one table is worked out from
+func NewGenomeJSON(g *genetics.Genome, sensors SensorSet) (GenomeJSON, error) {
+	j := GenomeJSON{ID: g.Id}
+	for _, t := range g.Traits {
+		j.Traits = append(j.Traits, TraitJSON{ID: t.Id, Params: t.Params})
+	}
+	for _, n := range g.Nodes {
+		activation, err := neatmath.NodeActivators.ActivationNameFromType(n.ActivationType)
+		if err != nil {
+			return j, fmt.Errorf("node %d: %w", n.Id, err)
+		}
+		j.Nodes = append(j.Nodes, NodeJSON{
+			ID:         n.Id,
+			Type:       network.NeuronTypeName(n.NeuronType),
+			Activation: activation,
+			Trait:      traitID(n.Trait),
+		})
+	}
+	for _, gene := range g.Genes {
+		l := gene.Link
+		j.Genes = append(j.Genes, GeneJSON{
+			In:          l.InNode.Id,
+			Out:         l.OutNode.Id,
+			Weight:      l.ConnectionWeight,
+			Recurrent:   l.IsRecurrent,
+			TimeDelayed: l.IsTimeDelayed,
+			Innovation:  gene.InnovationNum,
+			Mutation:    gene.MutationNum,
+			Enabled:     gene.IsEnabled,
+			Trait:       traitID(l.Trait),
+		})
+	}
+
+	copied, err := j.Genome()
+	if err != nil {
+		return j, err
+	}
+	net, err := copied.Genesis(g.Id)
+	if err != nil {
+		return j, fmt.Errorf("building network: %w", err)
+	}
+	j.MemoryOne, err = ExtractMemoryOneTable(net, sensors)
+	return j, err
+}
+
+// Genome builds the goNEAT genome back up
+func (j GenomeJSON) Genome() (*genetics.Genome, error) {
+	traits := map[int]*neat.Trait{}
+	var traitList []*neat.Trait
+	for _, t := range j.Traits {
+		trait := &neat.Trait{Id: t.ID, Params: append([]float64(nil), t.Params...)}
+		traits[t.ID] = trait
+		traitList = append(traitList, trait)
+	}
+
+	nodes := map[int]*network.NNode{}
+	var nodeList []*network.NNode
+	for _, n := range j.Nodes {
+		neuronType, err := network.NeuronTypeByName(n.Type)
+		if err != nil {
+			return nil, fmt.Errorf("node %d: %w", n.ID, err)
+		}
+		activation, err := neatmath.NodeActivators.ActivationTypeFromName(n.Activation)
+		if err != nil {
+			return nil, fmt.Errorf("node %d: %w", n.ID, err)
+		}
+		node := network.NewNNode(n.ID, neuronType)
+		node.ActivationType = activation
+		node.Trait = traits[n.Trait]
+		nodes[n.ID] = node
+		nodeList = append(nodeList, node)
+	}
+
+	var genes []*genetics.Gene
+	for _, g := range j.Genes {
+		in, out := nodes[g.In], nodes[g.Out]
+		if in == nil || out == nil {
+			return nil, fmt.Errorf("gene %d links nodes %d and %d which aren't in the genome", g.Innovation, g.In, g.Out)
+		}
+		link := network.NewLinkWithTrait(traits[g.Trait], g.Weight, in, out, g.Recurrent)
+		link.IsTimeDelayed = g.TimeDelayed
+		genes = append(genes, genetics.NewConnectionGene(link, g.Innovation, g.Mutation, g.Enabled))
+	}
+
+	return genetics.NewGenome(j.ID, traitList, nodeList, genes), nil
+}
+
+func traitID(t *neat.Trait) int {
+	if t == nil {
+		return 0
+	}
+	return t.Id
+}
+
+// PopulationDiversity is how differently the organisms in the population
+// play, the mean over every pair of them of the share of moves in their
+// BehaviorSignature that differ. A population that all plays the same way
+// is 0 however differently it is wired, one where every pair differs on
+// every move is 1. The organisms are given the inputs in sensors
+func PopulationDiversity(pop *genetics.Population, sensors SensorSet) (float64, error) {
+	signatures := make([][]int, 0, len(pop.Organisms))
+	for _, org := range pop.Organisms {
+		signature, err := BehaviorSignature(org.Phenotype, sensors)
+		if err != nil {
+			return 0, fmt.Errorf("organism %d: %w", org.Genotype.Id, err)
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatureDiversity(signatures), nil
+}
+
+func signatureDiversity(signatures [][]int) float64 {
+	total, pairs := 0.0, 0
+	for i := range signatures {
+		for j := i + 1; j < len(signatures); j++ {
+			total += signatureDistance(signatures[i], signatures[j])
+			pairs++
+		}
+	}
+	if pairs == 0 {
+		return 0
+	}
+	return total / float64(pairs)
+}
+
+// signatureDistance is the share of moves that differ between two
+// signatures, any length difference counts as moves that differ
+func signatureDistance(a, b []int) float64 {
+	longest, shortest := len(a), len(b)
+	if shortest > longest {
+		longest, shortest = shortest, longest
+	}
+	if longest == 0 {
+		return 0
+	}
+
+	differ := longest - shortest
+	for i := 0; i < shortest; i++ {
+		if a[i] != b[i] {
+			differ++
+		}
+	}
+	return float64(differ) / float64(longest)
+}
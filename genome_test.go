@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/yaricom/goNEAT/v2/neat/genetics"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// trainedGenome is a network shaped like the ones training builds, where
+// the last input goNEAT calls the bias is fed the opponent's move. A hidden
+// node with no input sits at 0.5 to stand in for a bias once it has been
+// activated, wired to the output with the given weight alongside the weights
+// from each move. Both moves are NoMove in the first round
+func trainedGenome(own, opponent, constant float64) string {
+	return fmt.Sprintf(`genomestart 1
+trait 1 0 0 0 0 0 0 0 0
+node 1 1 1 1 SigmoidSteepenedActivation
+node 2 1 1 3 SigmoidSteepenedActivation
+node 3 1 0 2 SigmoidSteepenedActivation
+node 4 1 0 0 SigmoidSteepenedActivation
+gene 1 1 3 %[1]v false 1 %[1]v true
+gene 1 2 3 %[2]v false 2 %[2]v true
+gene 1 1 4 0 false 3 0 true
+gene 1 4 3 %[3]v false 4 %[3]v true
+genomeend 1`, own, opponent, constant)
+}
+
+// cutOffGenome has its output fed only by a hidden node with no inputs, so
+// the output never activates and playing the network fails
+const cutOffGenome = `genomestart 1
+trait 1 0 0 0 0 0 0 0 0
+node 1 1 1 1 SigmoidSteepenedActivation
+node 2 1 1 3 SigmoidSteepenedActivation
+node 3 1 0 2 SigmoidSteepenedActivation
+node 4 1 0 0 SigmoidSteepenedActivation
+gene 1 4 3 1 false 1 1 true
+genomeend 1`
+
+func TestBehaviorSignature(t *testing.T) {
+	rest := strings.Repeat("C", DefaultRounds-1)
+	tests := []struct {
+		name   string
+		genome string
+		want   string // against ALLC, ALLD, TFT and the alternator
+	}{
+		{"ALLC", trainedGenome(0, 10, -30), strings.Repeat("C", 4*DefaultRounds)},
+		{"ALLD", trainedGenome(0, -10, 30), strings.Repeat("D", 4*DefaultRounds)},
+		{"TFT", trainedGenome(0, 10, -10), "C" + rest + "C" + strings.Repeat("D", DefaultRounds-1) + "C" + rest + "CCDCDCDCDC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, err := getGenome(tt.genome)
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, err := BehaviorSignature(net, DefaultSensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := movesString(first); got != tt.want {
+				t.Errorf("signature %s, want %s", got, tt.want)
+			}
+			second, err := BehaviorSignature(net, DefaultSensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(first, second) {
+				t.Errorf("signature changed from %s to %s", movesString(first), movesString(second))
+			}
+		})
+	}
+
+	net, err := getGenome(cutOffGenome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BehaviorSignature(net, DefaultSensors); err == nil {
+		t.Error("a network that can't play gave a signature")
+	}
+}
+
+func TestGenomeJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		genome string
+	}{
+		{"ALLC", trainedGenome(0, 10, -30)},
+		{"TFT", trainedGenome(0, 10, -10)},
+		{"champion", championGenome},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genome := readGenome(t, tt.genome)
+			exported, err := NewGenomeJSON(genome, DefaultSensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(exported)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var j GenomeJSON
+			if err := json.Unmarshal(data, &j); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(j, exported) {
+				t.Errorf("decoded\n%+v\nwant\n%+v", j, exported)
+			}
+
+			reloaded, err := j.Genome()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if GenomeFingerprint(reloaded) != GenomeFingerprint(genome) {
+				t.Error("reloaded genome has a different fingerprint")
+			}
+			original, err := genome.Genesis(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			net, err := reloaded.Genesis(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := BehaviorSignature(net, DefaultSensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := BehaviorSignature(original, DefaultSensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("reloaded genome plays %s, want %s", movesString(got), movesString(want))
+			}
+			table, err := ExtractMemoryOneTable(original, DefaultSensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if j.MemoryOne != table {
+				t.Errorf("memory one table %v, want %v", j.MemoryOne, table)
+			}
+		})
+	}
+}
+
+func TestPopulationDiversity(t *testing.T) {
+	allc, alld, tft := trainedGenome(0, 10, -30), trainedGenome(0, -10, 30), trainedGenome(0, 10, -10)
+	tests := []struct {
+		name    string
+		genomes []string
+		want    float64
+	}{
+		{"one", []string{tft}, 0},
+		{"identical", []string{allc, allc, allc}, 0},
+		// wired differently but playing the same
+		{"same play", []string{allc, trainedGenome(0, 20, -40), trainedGenome(5, 15, -50)}, 0},
+		{"opposites", []string{allc, alld}, 1},
+		// tit for tat differs from ALLC in 13 of the 40 moves and from
+		// ALLD in the other 27
+		{"varied", []string{allc, alld, tft}, (40.0 + 13 + 27) / 3 / 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pop := &genetics.Population{}
+			for _, genome := range tt.genomes {
+				pop.Organisms = append(pop.Organisms, newOrganism(t, genome))
+			}
+			got, err := PopulationDiversity(pop, DefaultSensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("diversity %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	pop := &genetics.Population{Organisms: []*genetics.Organism{newOrganism(t, allc), newOrganism(t, cutOffGenome)}}
+	if _, err := PopulationDiversity(pop, DefaultSensors); err == nil {
+		t.Error("a population with a network that can't play gave a diversity")
+	}
+}
+
+func TestGenomeFingerprint(t *testing.T) {
+	base := trainedGenome(1, 10, -10)
+	reordered := strings.Replace(base, "gene 1 1 3 1 false 1 1 true\ngene 1 2 3 10 false 2 10 true\n",
+		"gene 1 2 3 10 false 2 10 true\ngene 1 1 3 1 false 1 1 true\n", 1)
+	if reordered == base {
+		t.Fatal("genes weren't reordered")
+	}
+	tests := []struct {
+		name   string
+		genome string
+		same   bool
+	}{
+		{"same description", trainedGenome(1, 10, -10), true},
+		{"genes in another order", reordered, true},
+		{"disabled gene added", strings.Replace(base, "genomeend", "gene 1 2 4 7 false 5 7 false\ngenomeend", 1), true},
+		{"one weight changed", trainedGenome(1, 10, -10.5), false},
+		{"gene added", strings.Replace(base, "genomeend", "gene 1 2 4 7 false 5 7 true\ngenomeend", 1), false},
+		{"activation changed", strings.Replace(base, "node 4 1 0 0 SigmoidSteepenedActivation", "node 4 1 0 0 LinearActivation", 1), false},
+	}
+	want := GenomeFingerprint(readGenome(t, base))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GenomeFingerprint(readGenome(t, tt.genome)); (got == want) != tt.same {
+				t.Errorf("fingerprint %x against %x, same = %v", got, want, tt.same)
+			}
+		})
+	}
+}
+
+func readGenome(t *testing.T, genome string) *genetics.Genome {
+	t.Helper()
+	g, err := genetics.ReadGenome(strings.NewReader(genome), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
@@ -3,16 +3,20 @@ module titfortat
 go 1.17
 
 require (
+	github.com/sbinet/npyio v0.5.2
 	github.com/yaricom/goNEAT/v2 v2.9.3
 	golang.org/x/exp v0.0.0-20191030013958-a1ab85dbe136
+	gonum.org/v1/gonum v0.9.3
 )
 
 require (
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/sbinet/npyio v0.5.2 // indirect
 	github.com/spf13/cast v1.4.1 // indirect
-	gonum.org/v1/gonum v0.9.3 // indirect
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
+
+// npyio carries local changes to its readers and writers, see
+// third_party/npyio
+replace github.com/sbinet/npyio => ./third_party/npyio
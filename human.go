@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PlayHuman plays a game between a person typing their moves into in as
+// player A and the named strategy, printing each round to out as it goes.
+// If in runs out before the game is over the game stops there
+func PlayHuman(in io.Reader, out io.Writer, name string, opts GameOptions) (Game, error) {
+	bot, err := NewBot(name)
+	if err != nil {
+		return Game{}, err
+	}
+
+	game := NewGame(opts)
+	resetBot(bot)
+
+	_ = game.Play(gameDecision{
+		aChoice: NoMove,
+		bChoice: NoMove,
+	})
+
+	var turns []Turn
+	scanner := bufio.NewScanner(in)
+	for !game.GameOver() {
+		_, _ = fmt.Fprintf(out, "round %d of %d, C or D? ", game.Round+1, game.Rounds)
+		move, err := readMove(scanner, out)
+		if err == io.EOF {
+			_, _ = fmt.Fprintf(out, "\nstopped after %d rounds, you %d %s %d\n", game.Round, game.AScore, name, game.BScore)
+			return game, nil
+		}
+		if err != nil {
+			return game, err
+		}
+
+		state := game.State()
+		aScore, bScore := game.AScore, game.BScore
+		if err := game.Play(gameDecision{
+			aChoice: move,
+			bChoice: bot.Decision(state),
+		}); err != nil {
+			return game, err
+		}
+		turns = append(turns, Turn{
+			Round:   len(turns) + 1,
+			A:       game.APrevious,
+			B:       game.BPrevious,
+			APoints: game.AScore - aScore,
+			BPoints: game.BScore - bScore,
+			AScore:  game.AScore,
+			BScore:  game.BScore,
+		})
+		_, _ = fmt.Fprintf(out, "you %c %s %c, score you %d %s %d\n",
+			moveLetter(game.APrevious), name, moveLetter(game.BPrevious), game.AScore, name, game.BScore)
+	}
+
+	game.AwardCooperationBonus()
+	_, _ = fmt.Fprintln(out, explainTurns("you", name, game, turns))
+	return game, nil
+}
+
+// readMove reads lines until one is a move, telling the player off for
+// anything else. It returns io.EOF once there is nothing left to read
+func readMove(scanner *bufio.Scanner, out io.Writer) (int, error) {
+	for scanner.Scan() {
+		move, ok := parseMove(scanner.Text())
+		if ok {
+			return move, nil
+		}
+		_, _ = fmt.Fprint(out, "type C to cooperate or D to defect: ")
+	}
+	if err := scanner.Err(); err != nil {
+		return NoMove, err
+	}
+	return NoMove, io.EOF
+}
+
+func parseMove(s string) (int, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "c", "cooperate":
+		return Cooperate, true
+	case "d", "defect":
+		return Defect, true
+	}
+	return NoMove, false
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseMove(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"C", Cooperate, true},
+		{"d", Defect, true},
+		{" cooperate\r", Cooperate, true},
+		{"DEFECT", Defect, true},
+		{"", NoMove, false},
+		{"x", NoMove, false},
+		{"cd", NoMove, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseMove(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("parseMove(%q) = %d, %v, want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPlayHuman(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		rounds int
+		you    string // moves the person made
+		bot    string // TitForTatBot's answers
+		output string // something the person is told
+	}{
+		{"whole game", "c\nD\ncooperate\n", 3, "CDC", "CCD", "round 3 of 3"},
+		{"invalid input", "x\n\nC\nd\n", 2, "CD", "CC", "type C to cooperate or D to defect"},
+		{"runs out", "C\nD\n", 4, "CD", "CC", "stopped after 2 rounds"},
+		{"nothing typed", "", 4, "", "", "stopped after 0 rounds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			game, err := PlayHuman(strings.NewReader(tt.input), &out, "TitForTatBot", GameOptions{Rounds: tt.rounds})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := movesString(game.AHistory); got != tt.you {
+				t.Errorf("played %s, want %s", got, tt.you)
+			}
+			if got := movesString(game.BHistory); got != tt.bot {
+				t.Errorf("bot played %s, want %s", got, tt.bot)
+			}
+			if !strings.Contains(out.String(), tt.output) {
+				t.Errorf("output doesn't mention %q:\n%s", tt.output, out.String())
+			}
+		})
+	}
+
+	if _, err := PlayHuman(strings.NewReader("C\n"), &bytes.Buffer{}, "NoSuchBot", GameOptions{}); err == nil {
+		t.Error("played an unknown strategy")
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LeaderboardEntry is how one bot did against the opponents
+type LeaderboardEntry struct {
+	Name string
+	Standing
+}
+
+// EvaluateGenomeDir loads every genome in dir as a NeuralNetworkBot, named
+// after its file, and plays each of them against every opponent. Files
+// ending in .json are read as GenomeJSON and anything else in the format
+// goNEAT writes, like the best file saved during training. Files that are
+// neither, like the best.npz saved next to it, are skipped. The genomes
+// don't play each other. The leaderboard is ordered by score, highest first
+func EvaluateGenomeDir(ctx context.Context, dir string, opponents []Bot, opts TournamentOptions) ([]LeaderboardEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	genomes := map[string]Bot{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		ok, err := isGenomeFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		bot, err := loadGenomeBot(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		genomes[entry.Name()] = bot
+	}
+	if len(genomes) == 0 {
+		return nil, fmt.Errorf("no genomes in %s", dir)
+	}
+
+	columns := make(map[string]Bot, len(opponents))
+	for i, opponent := range opponents {
+		name := botType(opponent)
+		if _, ok := columns[name]; ok {
+			name = fmt.Sprintf("%s#%d", name, i)
+		}
+		columns[name] = opponent
+	}
+
+	result, err := RunCrossTournament(ctx, genomes, columns, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderboard := make([]LeaderboardEntry, 0, len(result.Rows))
+	for _, name := range result.Rows {
+		leaderboard = append(leaderboard, LeaderboardEntry{Name: name, Standing: result.Standing(name)})
+	}
+	sort.SliceStable(leaderboard, func(i, j int) bool {
+		return leaderboard[i].Score > leaderboard[j].Score
+	})
+	return leaderboard, nil
+}
+
+// isGenomeFile says whether the file at path is meant to be a genome, a
+// .json file holding nodes or one that starts out like goNEAT's format,
+// without checking the genome in it is sound
+func isGenomeFile(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var fields map[string]json.RawMessage
+		if err := json.NewDecoder(file).Decode(&fields); err != nil {
+			return false, nil
+		}
+		_, ok := fields["nodes"]
+		return ok, nil
+	}
+
+	// goNEAT's reader skips comments like the one the best file starts with
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, err
+	}
+	text := strings.TrimSpace(string(head[:n]))
+	for strings.HasPrefix(text, "/*") {
+		end := strings.Index(text, "*/")
+		if end < 0 {
+			return false, nil
+		}
+		text = strings.TrimSpace(text[end+2:])
+	}
+	return strings.HasPrefix(text, "genomestart"), nil
+}
+
+// loadGenomeBot builds a NeuralNetworkBot from the genome saved at path
+func loadGenomeBot(path string) (*NeuralNetworkBot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return NewNeuralNetworkBotFromReader(file)
+	}
+
+	var j GenomeJSON
+	if err := json.NewDecoder(file).Decode(&j); err != nil {
+		return nil, fmt.Errorf("reading genome: %w", err)
+	}
+	genome, err := j.Genome()
+	if err != nil {
+		return nil, err
+	}
+	return NewNeuralNetworkBot(genome)
+}
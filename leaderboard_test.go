@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEvaluateGenomeDir(t *testing.T) {
+	dir := t.TempDir()
+	allc, err := NewGenomeJSON(readGenome(t, trainedGenome(0, 10, -30)), DefaultSensors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(allc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"alld.genome": trainedGenome(0, -10, 30),
+		"allc.json":   string(data),
+		"tft":         trainedGenome(0, 10, -10),
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// directories and whatever else training leaves around are skipped
+	if err := os.Mkdir(filepath.Join(dir, "old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	others := map[string]string{
+		"best.npz":      "PK\x03\x04\x14\x00\x00\x00",
+		"manifest.json": `{"bots": ["TitForTatBot"]}`,
+		"notes.txt":     "/* not a genome */ just notes",
+	}
+	for name, contents := range others {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opponents := []Bot{CooperateBot{}, TitForTatBot{}, TitForTatBot{}}
+	got, err := EvaluateGenomeDir(context.Background(), dir, opponents, TournamentOptions{Games: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the cooperators draw every game on the reward, always defecting
+	// takes the temptation from each opponent once and then is punished by
+	// tit for tat, and ties keep the order of the names
+	want := []LeaderboardEntry{
+		{"allc.json", Standing{Games: 3, Draws: 3, Score: 30}},
+		{"tft", Standing{Games: 3, Draws: 3, Score: 30}},
+		{"alld.genome", Standing{Games: 3, Wins: 3, Score: 30 - 6 - 6}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		if _, err := EvaluateGenomeDir(context.Background(), t.TempDir(), opponents, TournamentOptions{Games: 1}); err == nil {
+			t.Error("evaluated a directory with no genomes")
+		}
+	})
+	t.Run("no genomes", func(t *testing.T) {
+		others := t.TempDir()
+		if err := os.WriteFile(filepath.Join(others, "best"), []byte("not a genome"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := EvaluateGenomeDir(context.Background(), others, opponents, TournamentOptions{Games: 1}); err == nil {
+			t.Error("evaluated a directory with no genomes")
+		}
+	})
+	t.Run("broken", func(t *testing.T) {
+		broken := t.TempDir()
+		if err := os.WriteFile(filepath.Join(broken, "best"), []byte(championGenome[:len(championGenome)/2]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := EvaluateGenomeDir(context.Background(), broken, opponents, TournamentOptions{Games: 1}); err == nil {
+			t.Error("evaluated a genome that was cut off")
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"github.com/yaricom/goNEAT/v2/neat"
+	"io"
+)
+
+// LogLevel is how much the evaluator reports as it goes
+type LogLevel int
+
+const (
+	LogQuiet    LogLevel = iota // nothing at all
+	LogProgress                 // new winners and anything that went wrong
+	LogVerbose                  // everything
+)
+
+// Event is something the evaluator has to report
+type Event struct {
+	Level      LogLevel // least verbose level the event is shown at
+	Message    string
+	Trial      int
+	Generation int
+	Genome     int // the genome the event is about, if Nodes is set
+	Fitness    float64
+	Nodes      int
+	Genes      int
+	Diversity  float64 // see PopulationDiversity, only on diversity events
+	Err        error
+}
+
+func (e Event) String() string {
+	s := fmt.Sprintf("trial %d generation %d: %s", e.Trial, e.Generation, e.Message)
+	if e.Nodes != 0 {
+		s += fmt.Sprintf(" genome %d fitness %.3f nodes %d genes %d", e.Genome, e.Fitness, e.Nodes, e.Genes)
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// Logger receives every event the evaluator reports and decides what to
+// do with it
+type Logger interface {
+	Log(event Event)
+}
+
+// NewLogger writes every event at or below level to w as a line of text
+func NewLogger(w io.Writer, level LogLevel) Logger {
+	return writerLogger{w: w, level: level}
+}
+
+type writerLogger struct {
+	w     io.Writer
+	level LogLevel
+}
+
+func (l writerLogger) Log(event Event) {
+	if event.Level > l.level {
+		return
+	}
+	_, _ = fmt.Fprintln(l.w, event)
+}
+
+// neatLogger hands events to goNEAT's own logging, so they follow
+// the log_level in the NEAT options
+type neatLogger struct{}
+
+func (l neatLogger) Log(event Event) {
+	if event.Err != nil {
+		neat.ErrorLog(event.String())
+		return
+	}
+	if event.Level == LogVerbose {
+		neat.DebugLog(event.String())
+		return
+	}
+	neat.InfoLog(event.String())
+}
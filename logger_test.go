@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"github.com/yaricom/goNEAT/v2/experiment"
+	"github.com/yaricom/goNEAT/v2/neat"
+	"github.com/yaricom/goNEAT/v2/neat/genetics"
+	"os"
+	"strings"
+	"testing"
+)
+
+// chdirTemp moves into a temporary directory for the rest of the test, for
+// code that writes files to the working directory
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestLoggerLevels(t *testing.T) {
+	// a generation saves the best genome to the working directory
+	chdirTemp(t)
+
+	tests := []struct {
+		name    string
+		level   LogLevel
+		want    []string
+		notWant []string
+	}{
+		{"quiet", LogQuiet, nil, []string{"new winner", "diversity", "saved best genome"}},
+		{"progress", LogProgress, []string{"new winner", "behavioral diversity"}, []string{"saved best genome"}},
+		{"verbose", LogVerbose, []string{"new winner", "behavioral diversity", "saved best genome"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := PrisonersDilemmaGenerationEvaluator{Logger: NewLogger(&buf, tt.level), Diversity: true}
+			// always defecting against CooperateBot is a winner
+			pop := &genetics.Population{Organisms: []*genetics.Organism{
+				newOrganism(t, memoryOneGenome(0, 0, 10)),
+				newOrganism(t, memoryOneGenome(0, 0, -10)),
+			}}
+			if err := e.GenerationEvaluate(pop, &experiment.Generation{Id: 1}, &neat.Options{PopSize: 2}); err != nil {
+				t.Fatal(err)
+			}
+
+			out := buf.String()
+			if tt.level == LogQuiet && out != "" {
+				t.Errorf("quiet logger got\n%s", out)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output doesn't mention %q:\n%s", s, out)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(out, s) {
+					t.Errorf("output mentions %q:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
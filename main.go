@@ -1,20 +1,69 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"flag"
 	"fmt"
-	"github.com/yaricom/goNEAT/v2/experiment"
 	"github.com/yaricom/goNEAT/v2/neat"
-	"github.com/yaricom/goNEAT/v2/neat/genetics"
 	"golang.org/x/exp/rand"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
 	"time"
 )
 
 func main() {
-	seed := time.Now().Unix()
-	rand.Seed(uint64(seed))
+	explain := flag.String("explain", "", "play one game between two strategies and explain it, e.g. TitForTatBot,DefectBot")
+	records := flag.String("records", "", "stream every tournament game to this file as newline delimited JSON")
+	ecology := flag.String("ecology", "", "write the replicator dynamics of the tournament to this .npy file")
+	cooperation := flag.String("cooperation", "", "write how often each pair of bots both cooperated to this .csv or .npy file")
+	manifest := flag.String("manifest", "", "write what is needed to rerun the tournament to this file as JSON")
+	play := flag.String("play", "", "play a game against this strategy, typing C or D each round")
+	replay := flag.String("replay", "", "run the tournament in this manifest again, streaming its games to -records if set")
+	seeds := flag.String("seeds", "", "train once per seed in this comma separated list and report how the champions vary")
+	seed := flag.Uint64("seed", 0, "seed for the random source the bots draw from, taken from the clock if unset")
+	flag.Parse()
+
+	// the global source starts from the same seed every run, so without this
+	// the random bots in -play and -explain would always make the same moves
+	if *seed == 0 {
+		*seed = uint64(time.Now().UnixNano())
+	}
+	rand.Seed(*seed)
+
+	// interrupting stops training and the tournament cleanly, whatever was
+	// finished still gets reported
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if *explain != "" {
+		names := strings.Split(*explain, ",")
+		if len(names) != 2 {
+			log.Fatal("-explain needs two strategies separated by a comma, one of: ", strings.Join(StrategyNames(), ", "))
+		}
+		if _, err := Explain(os.Stdout, names[0], names[1]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *replay != "" {
+		if err := replayManifest(ctx, *replay, *records); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *play != "" {
+		if _, err := PlayHuman(os.Stdin, os.Stdout, *play, GameOptions{}); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	// Load neatOptions configuration
 	configFile, err := os.Open("./xor.neat")
@@ -26,214 +75,165 @@ func main() {
 		log.Fatal("Failed to load NEAT options: ", err)
 	}
 
-	exp := experiment.Experiment{
-		Id:       0,
-		Trials:   make(experiment.Trials, 100),
-		RandSeed: seed,
-	}
+	var evaluator PrisonersDilemmaGenerationEvaluator
 
-	exp.MaxFitnessScore = 16
+	if *seeds != "" {
+		var list []int64
+		for _, s := range strings.Split(*seeds, ",") {
+			seed, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				log.Fatal("-seeds needs a comma separated list of integers: ", err)
+			}
+			list = append(list, seed)
+		}
 
-	var evaluator PrisonersDilemmaGenerationEvaluator
-	// This special constructor creates a Genome with in inputs, out outputs, n out of maxHidden hidden units, and random
-	// connectivity.  If rec is true then recurrent connections will be included. The last input is a bias
-	// link_prob is the probability of a link. The created genome is not modular.
-	// newId, in, out, n, maxHidden int, recurrent bool, linkProb float64
-	genomeRand := genetics.NewGenomeRand(0, 2, 1, 1, 10, false, 0.7)
-
-	ctx, _ := context.WithCancel(context.Background())
-	err = exp.Execute(neat.NewContext(ctx, options), genomeRand, evaluator, nil)
+		results, err := SeedSweep(ctx, options, evaluator, list)
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+		if err := PrintSweep(os.Stdout, results); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	exp, err := train(ctx, options, evaluator, time.Now().Unix())
 	if err != nil {
 		fmt.Println(err.Error())
 	}
 
+	exp.MaxFitnessScore = 16
 	exp.PrintStatistics()
 
-	runGames()
+	runGames(ctx, *records, *ecology, *cooperation, *manifest)
 }
 
-type PrisonersDilemmaGenerationEvaluator struct{}
-
-func (ex PrisonersDilemmaGenerationEvaluator) GenerationEvaluate(
-	pop *genetics.Population,
-	epoch *experiment.Generation,
-	context *neat.Options,
-) (err error) {
-	// Calculate the fitness of all organisms in the population
-	// going to fight against RandomBot
-	for _, org := range pop.Organisms {
-		res, err := ex.orgEvaluate(org)
+// https://github.com/yaricom/goNEAT/blob/master/executor.go
+// https://maori.geek.nz/learning-to-play-asteroids-in-golang-with-neat-f44c3472938f
+func runGames(ctx context.Context, recordsPath, ecologyPath, cooperationPath, manifestPath string) {
+	opts := TournamentOptions{Games: 100_000, Seed: uint64(time.Now().UnixNano())}
+	if recordsPath != "" {
+		file, err := os.Create(recordsPath)
 		if err != nil {
-			return err
+			log.Fatal("Failed to create records file: ", err)
 		}
+		defer file.Close()
 
-		if res && (epoch.Best == nil || org.Fitness > epoch.Best.Fitness) {
-			epoch.Solved = true
-			epoch.WinnerNodes = len(org.Genotype.Nodes)
-			epoch.WinnerGenes = org.Genotype.Extrons()
-			epoch.WinnerEvals = context.PopSize*epoch.Id + org.Genotype.Id
-			epoch.Best = org
-			if epoch.WinnerNodes == 5 {
-				neat.InfoLog(fmt.Sprintf("Dumped optimal genome\n"))
-			}
-		}
+		w := bufio.NewWriter(file)
+		defer w.Flush()
+		opts.Records = w
 	}
 
-	epoch.FillPopulationStatistics(pop)
+	// every seat gets a fresh bot from the same constructors a replay of the
+	// manifest uses, so the network bot's state never crosses between games
+	// played at once
+	names := []string{
+		"RandomBot",
+		"TitForTatBot",
+		"DefectBot",
+		"CooperateBot",
+		"RandomDefectBot",
+		"TitForTatBotReverse",
+		"OftenRandomDefectBot",
+		"MirrorBot",
+		"NeuralNetworkBot",
+	}
+	constructors := make(map[string]func() Bot, len(names))
+	for _, name := range names {
+		constructors[name] = strategies[name]
+	}
 
-	// if we have a best candidate now save it
-	if epoch.Best != nil {
-		//bestOrgPath := fmt.Sprintf("best_%v_%04d", epoch.TrialId, epoch.Id)
-		bestOrgPath := "best"
-		file, err := os.Create(bestOrgPath)
-		if err != nil {
-			neat.ErrorLog(fmt.Sprintf("Failed to dump population, reason: %s\n", err))
-		} else {
-			org := epoch.Best
-			_, _ = fmt.Fprintf(file, "/* Organism #%d Fitness: %.3f Error: %.3f */\n",
-				org.Genotype.Id, org.Fitness, org.Error)
-			_ = org.Genotype.Write(file)
+	if manifestPath != "" {
+		if err := writeManifest(manifestPath, NewManifest(names, opts)); err != nil {
+			log.Fatal("Failed to write manifest: ", err)
 		}
 	}
 
-	return nil
-}
+	result, err := RunTournamentOf(ctx, constructors, opts)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
 
-func (e *PrisonersDilemmaGenerationEvaluator) orgEvaluate(organism *genetics.Organism) (bool, error) {
-	game := CreateGame()
-	b := CooperateBot{}
+	for _, k := range result.Bots() {
+		standing := result.Standing(k)
+		fmt.Println()
+		fmt.Println(k, "winRate", standing.WinRate())
+		fmt.Println(k, "lossRate", standing.LossRate())
+		fmt.Println(k, "drawRate", standing.DrawRate())
 
-	netDepth, _ := organism.Phenotype.MaxActivationDepthFast(0) // The max depth of the network to be activated
+		fmt.Println(k, "win+DrawRate", standing.WinRate()+standing.DrawRate())
+	}
 
-	for !game.GameOver() {
-		// get the game state
-		state := game.State()
+	fmt.Println("")
+	for _, k := range result.Bots() {
+		fmt.Println(k, "score", result.Standing(k).Score)
+	}
 
-		// set up our input
-		err := organism.Phenotype.LoadSensors([]float64{
-			float64(state.aPrevious),
-			float64(state.bPrevious),
-		})
-		if err != nil {
-			return false, err
+	draws := result.AllDrawMatchups()
+	if len(draws) != 0 {
+		fmt.Println("")
+		for _, m := range draws {
+			fmt.Println(m.A, "vs", m.B, "drew every game")
 		}
+	}
 
-		// run the network
-		_, err = organism.Phenotype.ForwardSteps(netDepth)
-		if err != nil {
-			return false, err
+	if ecologyPath != "" {
+		if err := ReplicatorDynamics(result, 1000).Write(ecologyPath); err != nil {
+			log.Fatal("Failed to write ecology: ", err)
 		}
+	}
 
-		// based on what the network says play!
-		decision := Cooperate
-		if organism.Phenotype.Outputs[0].Activation > 0.5 {
-			decision = Defect
+	if cooperationPath != "" {
+		if err := result.CooperationMatrix().Write(cooperationPath); err != nil {
+			log.Fatal("Failed to write cooperation matrix: ", err)
 		}
-
-		game.Play(gameDecision{
-			aChoice: decision,
-			bChoice: b.Decision(state),
-		})
 	}
+}
 
-	organism.Fitness = float64(game.AScore)
-	organism.Error = 0.0
-	organism.IsWinner = game.AScore > 20
+func writeManifest(path string, m Manifest) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
 
-	return organism.IsWinner, nil
+	if err := m.Write(file); err != nil {
+		return err
+	}
+	return file.Close()
 }
 
-// https://github.com/yaricom/goNEAT/blob/master/executor.go
-// https://maori.geek.nz/learning-to-play-asteroids-in-golang-with-neat-f44c3472938f
-func runGames() {
-	rand.Seed(uint64(time.Now().UnixNano()))
-
-	nnbot := NeuralNetworkBot{
-		net: getGenome(`/* Organism #0 Fitness: 33.000 Error: 0.000 */
-genomestart 0
-trait 1 0 0 0 0 0 0 0 0
-node 1 1 1 1 SigmoidSteepenedActivation
-node 2 1 1 3 SigmoidSteepenedActivation
-node 3 1 0 0 SigmoidSteepenedActivation
-node 13 1 0 2 SigmoidSteepenedActivation
-gene 1 2 3 0.47155578767902206 false 27 0.47155578767902206 true
-gene 1 2 13 -0.024576662955294593 false 157 -0.024576662955294593 true
-gene 1 3 13 1.4502147215405494 false 158 1.4502147215405494 true
-genomeend 0
-`)}
-
-	// create the bots and play them against each other and print how they did over 1000 games
-	bots := map[string]Bot{
-		"RandomBot":            RandomBot{},
-		"TitForTatBot":         TitForTatBot{},
-		"DefectBot":            DefectBot{},
-		"CooperateBot":         CooperateBot{},
-		"RandomDefectBot":      RandomDefectBot{},
-		"TitForTatBotReverse":  TitForTatBotReverse{},
-		"OftenRandomDefectBot": OftenRandomDefectBot{},
-		"NeuralNetworkBot":     nnbot,
-	}
-
-	winRates := map[string]float64{}
-	lossRates := map[string]float64{}
-	drawRates := map[string]float64{}
-
-	scoreRates := map[string]int{}
-
-	for k1, b1 := range bots {
-		// now run X times and see how they go
-		k1Wins := 0
-		k1Loses := 0
-		k1Draws := 0
-
-		gameTurns := 100_000
-		for _, b2 := range bots {
-			for i := 0; i < gameTurns; i++ {
-				game := CreateGame()
-
-				game.Play(gameDecision{
-					aChoice: -1,
-					bChoice: -1,
-				})
-
-				for !game.GameOver() {
-					state := game.State()
-					game.Play(gameDecision{
-						aChoice: b1.Decision(state),
-						bChoice: b2.Decision(state),
-					})
-				}
-
-				if game.AScore == game.BScore {
-					k1Draws++
-				}
-				if game.AScore > game.BScore {
-					k1Wins++
-				}
-				if game.AScore < game.BScore {
-					k1Loses++
-				}
-
-				scoreRates[k1] += game.AScore
-			}
-		}
+func replayManifest(ctx context.Context, path, recordsPath string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
 
-		winRates[k1] = (float64(k1Wins) / float64(gameTurns*len(bots))) * 100
-		lossRates[k1] = (float64(k1Loses) / float64(gameTurns*len(bots))) * 100
-		drawRates[k1] = (float64(k1Draws) / float64(gameTurns*len(bots))) * 100
+	m, err := ReadManifest(file)
+	if err != nil {
+		return err
 	}
 
-	for k, v := range winRates {
-		fmt.Println()
-		fmt.Println(k, "winRate", v)
-		fmt.Println(k, "lossRate", lossRates[k])
-		fmt.Println(k, "drawRate", drawRates[k])
+	var traces io.Writer
+	if recordsPath != "" {
+		out, err := os.Create(recordsPath)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
 
-		fmt.Println(k, "win+DrawRate", v+drawRates[k])
+		w := bufio.NewWriter(out)
+		defer w.Flush()
+		traces = w
 	}
 
-	fmt.Println("")
-	for k, v := range scoreRates {
-		fmt.Println(k, "score", v)
+	result, err := Replay(ctx, m, traces)
+	if err != nil {
+		return err
 	}
+	for _, k := range result.Bots() {
+		fmt.Println(k, "score", result.Standing(k).Score)
+	}
+	return nil
 }
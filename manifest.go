@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/yaricom/goNEAT/v2/neat/genetics"
+	"io"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+// Manifest is everything needed to run a tournament again. Bots are made by
+// name with NewBot unless they are in Genomes, which maps the name of a
+// neural network bot to the file holding its genome
+type Manifest struct {
+	Version string            `json:"version"`
+	Seed    uint64            `json:"seed"`
+	Games   int               `json:"games"`
+	Rounds  int               `json:"rounds"`
+	Warmup  int               `json:"warmup_rounds,omitempty"`
+	Payoff  Payoff            `json:"payoff"`
+	BPayoff *Payoff           `json:"b_payoff,omitempty"`
+	Bounds  *ScoreBounds      `json:"score_bounds,omitempty"`
+	Seating bool              `json:"randomize_seating,omitempty"`
+	Bonus   int               `json:"cooperation_bonus,omitempty"`
+	Ties    TiePolicy         `json:"ties,omitempty"`
+	Bots    []string          `json:"bots"`
+	Genomes map[string]string `json:"genomes,omitempty"`
+}
+
+// NewManifest records how a tournament between the named bots was run
+func NewManifest(bots []string, opts TournamentOptions) Manifest {
+	game := NewGame(opts.GameOptions)
+	games := opts.Games
+	if games <= 0 {
+		games = 100_000
+	}
+
+	names := append([]string(nil), bots...)
+	sort.Strings(names)
+
+	return Manifest{
+		Version: version(),
+		Seed:    opts.Seed,
+		Games:   games,
+		Rounds:  game.Rounds,
+		Warmup:  game.WarmupRounds,
+		Payoff:  game.Payoff,
+		BPayoff: game.BPayoff,
+		Bounds:  game.ScoreBounds,
+		Seating: opts.RandomizeSeating,
+		Bonus:   game.CooperationBonus,
+		Ties:    opts.Ties,
+		Bots:    names,
+	}
+}
+
+// ReadManifest reads a manifest written by Manifest.Write
+func ReadManifest(r io.Reader) (Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return m, fmt.Errorf("reading manifest: %w", err)
+	}
+	return m, nil
+}
+
+func (m Manifest) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// Options are the tournament options the manifest was made from
+func (m Manifest) Options() TournamentOptions {
+	payoff := m.Payoff
+	return TournamentOptions{
+		GameOptions: GameOptions{
+			Rounds:           m.Rounds,
+			Payoff:           &payoff,
+			BPayoff:          m.BPayoff,
+			WarmupRounds:     m.Warmup,
+			ScoreBounds:      m.Bounds,
+			CooperationBonus: m.Bonus,
+		},
+		Games:            m.Games,
+		Seed:             m.Seed,
+		RandomizeSeating: m.Seating,
+		Ties:             m.Ties,
+	}
+}
+
+// Constructors returns a function for every strategy in the manifest that
+// makes a fresh bot playing it, genomes are only read the once
+func (m Manifest) Constructors() (map[string]func() Bot, error) {
+	constructors := make(map[string]func() Bot, len(m.Bots))
+	for _, name := range m.Bots {
+		create, err := m.constructor(name)
+		if err != nil {
+			return nil, err
+		}
+		constructors[name] = create
+	}
+	return constructors, nil
+}
+
+func (m Manifest) constructor(name string) (func() Bot, error) {
+	path, ok := m.Genomes[name]
+	if !ok {
+		create, ok := strategies[name]
+		if !ok {
+			_, err := NewBot(name)
+			return nil, err
+		}
+		return create, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	genome, err := genetics.ReadGenome(file, 1)
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading genome: %w", path, err)
+	}
+	if _, err := NewNeuralNetworkBot(genome); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return func() Bot {
+		// the genome built a bot above so it always will
+		bot, _ := NewNeuralNetworkBot(genome)
+		return bot
+	}, nil
+}
+
+// Replay runs the tournament the manifest describes again, with a fresh bot
+// in every seat of every matchup as it was recorded. If traces is set
+// every game played is written to it as a GameRecord, so a surprising
+// result can be looked into after the fact
+func Replay(ctx context.Context, m Manifest, traces io.Writer) (TournamentResult, error) {
+	if err := m.Validate(); err != nil {
+		return TournamentResult{}, err
+	}
+	constructors, err := m.Constructors()
+	if err != nil {
+		return TournamentResult{}, err
+	}
+
+	opts := m.Options()
+	opts.Records = traces
+	return RunTournamentOf(ctx, constructors, opts)
+}
+
+// Validate checks the tournament could be run without playing any of it,
+// that every bot can be made, every genome read and the payoffs are a
+// dilemma. Every problem found is reported in a single ValidationError
+func (m Manifest) Validate() error {
+	var problems []error
+	if len(m.Bots) == 0 {
+		problems = append(problems, ErrNoOpponents)
+	}
+	for _, name := range m.Bots {
+		if _, err := m.constructor(name); err != nil {
+			problems = append(problems, fmt.Errorf("bot %s: %w", name, err))
+		}
+	}
+	for name := range m.Genomes {
+		if !contains(m.Bots, name) {
+			problems = append(problems, fmt.Errorf("genome for %s which is not one of the bots", name))
+		}
+	}
+
+	if !AnalyzePayoff(m.Payoff).Dilemma {
+		problems = append(problems, fmt.Errorf("payoff %+v is not a prisoner's dilemma", m.Payoff))
+	}
+	if m.BPayoff != nil && !AnalyzePayoff(*m.BPayoff).Dilemma {
+		problems = append(problems, fmt.Errorf("b payoff %+v is not a prisoner's dilemma", *m.BPayoff))
+	}
+	if m.Rounds < 0 || m.Games < 0 {
+		problems = append(problems, fmt.Errorf("%d rounds and %d games can't be played", m.Rounds, m.Games))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return ValidationError{Problems: problems}
+}
+
+// ValidationError is every problem Validate found
+type ValidationError struct {
+	Problems []error
+}
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Error()
+	}
+	return "invalid tournament: " + strings.Join(messages, "; ")
+}
+
+func (e ValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// version is the version of the module the binary was built from
+func version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(unknown)"
+	}
+	return info.Main.Version
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	modest := DefaultPayoff
+	modest.Temptation = 2
+	opts := TournamentOptions{
+		GameOptions: GameOptions{
+			Rounds:           20,
+			BPayoff:          &modest,
+			WarmupRounds:     2,
+			ScoreBounds:      &ScoreBounds{Min: -30, Max: 30},
+			CooperationBonus: 1,
+		},
+		Games:            25,
+		Seed:             3,
+		RandomizeSeating: true,
+		Ties:             TieCooperation,
+	}
+	names := []string{"TitForTatBot", "RandomBot", "GrofmanBot", "DefectBot"}
+	m := NewManifest(names, opts)
+
+	var buf bytes.Buffer
+	if err := m.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadManifest(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, m) {
+		t.Fatalf("read back\n%+v\nwrote\n%+v", read, m)
+	}
+
+	constructors := map[string]func() Bot{}
+	for _, name := range names {
+		constructors[name] = strategies[name]
+	}
+	original, err := RunTournamentOf(context.Background(), constructors, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := Replay(context.Background(), read, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !replayed.Equal(original) {
+		t.Error("replaying the manifest read back doesn't match the original tournament")
+	}
+}
+
+func TestManifestValidate(t *testing.T) {
+	dir := t.TempDir()
+	champion := filepath.Join(dir, "champion")
+	broken := filepath.Join(dir, "broken")
+	if err := os.WriteFile(champion, []byte(championGenome), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(broken, []byte(championGenome[:len(championGenome)/2]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	notDilemma := DefaultPayoff
+	notDilemma.Temptation = 5
+
+	valid := func() Manifest {
+		m := NewManifest([]string{"TitForTatBot", "champion"}, TournamentOptions{})
+		m.Genomes = map[string]string{"champion": champion}
+		return m
+	}
+	tests := []struct {
+		name   string
+		change func(m *Manifest)
+		want   []string
+	}{
+		{"valid", func(m *Manifest) {}, nil},
+		{"unparseable genome", func(m *Manifest) { m.Genomes["champion"] = broken }, []string{"bot champion: " + broken + ": reading genome"}},
+		{"missing genome", func(m *Manifest) { m.Genomes["champion"] = filepath.Join(dir, "missing") }, []string{"bot champion"}},
+		{"not a dilemma", func(m *Manifest) { m.Payoff = notDilemma }, []string{"is not a prisoner's dilemma"}},
+		{"b not a dilemma", func(m *Manifest) { m.BPayoff = &notDilemma }, []string{"b payoff"}},
+		{"unknown bot", func(m *Manifest) { m.Bots = append(m.Bots, "NoSuchBot") }, []string{"bot NoSuchBot"}},
+		{"genome for nobody", func(m *Manifest) { m.Genomes["nobody"] = champion }, []string{"genome for nobody"}},
+		{"everything at once", func(m *Manifest) {
+			m.Genomes["champion"] = broken
+			m.Payoff = notDilemma
+		}, []string{"reading genome", "is not a prisoner's dilemma"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid()
+			tt.change(&m)
+			err := m.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var invalid ValidationError
+			if !errors.As(err, &invalid) || len(invalid.Problems) != len(tt.want) {
+				t.Fatalf("got %v, want %d problems", err, len(tt.want))
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q doesn't mention %q", err, want)
+				}
+			}
+		})
+	}
+
+	if err := (Manifest{Payoff: DefaultPayoff}).Validate(); !errors.Is(err, ErrNoOpponents) {
+		t.Errorf("no bots gave %v, want %v", err, ErrNoOpponents)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	m := NewManifest([]string{
+		"FixedMixedBot",
+		"RandomBot",
+		"ShubikBot",
+		"TidemanChieruzziBot",
+		"TitForTatBot",
+	}, TournamentOptions{Games: 40, Seed: 11})
+
+	first, err := Replay(context.Background(), m, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Replay(context.Background(), m, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Equal(second) {
+		t.Error("replaying the same manifest twice gave different results")
+	}
+
+	// recorded with bots built the way runGames builds them
+	constructors := map[string]func() Bot{}
+	for _, name := range m.Bots {
+		constructors[name] = strategies[name]
+	}
+	recorded, err := RunTournamentOf(context.Background(), constructors, m.Options())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !recorded.Equal(first) {
+		t.Error("replay doesn't match the tournament the manifest was recorded from")
+	}
+
+	for _, matchup := range first.Matchups {
+		// seats drawing their types apart can't always cooperate together
+		if matchup.A == "FixedMixedBot" && matchup.B == "FixedMixedBot" &&
+			matchup.MutualCooperations == matchup.ACooperations &&
+			matchup.ACooperations == matchup.BCooperations {
+			t.Error("both seats of FixedMixedBot against itself drew the same type every game")
+		}
+	}
+}
+
+func TestReplayNeuralNetworkBot(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "champion.genome")
+	if err := os.WriteFile(path, []byte(championGenome), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"NeuralNetworkBot", "TitForTatBot", "RandomBot", "champion"}
+	opts := TournamentOptions{Games: 30, Seed: 7, Workers: 4}
+	m := NewManifest(names, opts)
+	m.Genomes = map[string]string{"champion": path}
+
+	// recorded the way runGames plays it, a fresh bot in every seat and
+	// several matchups at once
+	constructors := map[string]func() Bot{}
+	for _, name := range names[:3] {
+		constructors[name] = strategies[name]
+	}
+	constructors["champion"] = func() Bot {
+		bot, _ := NewNeuralNetworkBotFromReader(strings.NewReader(championGenome))
+		return bot
+	}
+	recorded, err := RunTournamentOf(context.Background(), constructors, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, err := Replay(context.Background(), m, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !replayed.Equal(recorded) {
+		t.Error("replaying the manifest doesn't match the tournament it was recorded from")
+	}
+}
+
+func TestManifestConstructorsMakeFreshBots(t *testing.T) {
+	m := Manifest{Bots: []string{"ShubikBot"}}
+	constructors, err := m.Constructors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := constructors["ShubikBot"](), constructors["ShubikBot"]()
+	if sameInstance(a, b) {
+		t.Error("constructor returned the same bot twice")
+	}
+
+	if _, err := (Manifest{Bots: []string{"NoSuchBot"}}).Constructors(); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Turn is the record of one round of a traced game
+type Turn struct {
+	Round   int
+	A       int // move played by A
+	B       int // move played by B
+	APoints int // what A scored this round
+	BPoints int // what B scored this round
+	AScore  int // A's running total
+	BScore  int // B's running total
+}
+
+// PlayGame resets both bots and plays a full game between them with a in
+// the seat of player A. If either bot makes an invalid move the game ends
+// there
+func PlayGame(a, b Bot, opts GameOptions) Game {
+	game, _, _ := playGame(a, b, opts, false)
+	return game
+}
+
+// PlayTraced plays a game like PlayGame and also returns every round
+// played, it never stops early
+func PlayTraced(a, b Bot, opts GameOptions) (Game, []Turn) {
+	game, turns, _ := playGame(a, b, opts, true)
+	return game, turns
+}
+
+func playGame(a, b Bot, opts GameOptions, trace bool) (Game, []Turn, error) {
+	game := NewGame(opts)
+	resetBot(a)
+	resetBot(b)
+
+	_ = game.Play(gameDecision{
+		aChoice: NoMove,
+		bChoice: NoMove,
+	})
+
+	aConstant, aFixed := constantMove(a)
+	bConstant, bFixed := constantMove(b)
+
+	// projecting the last round only holds if both would keep playing it
+	earlyStop := opts.EarlyStop && !trace && stationary(a) && stationary(b)
+
+	var turns []Turn
+	for !game.GameOver() {
+		state := game.State()
+		aScore, bScore := game.AScore, game.BScore
+
+		aMove, bMove := aConstant, bConstant
+		if !aFixed {
+			aMove = a.Decision(state.Swap())
+		}
+		if !bFixed {
+			bMove = b.Decision(state)
+		}
+		err := game.Play(gameDecision{
+			aChoice: aMove,
+			bChoice: bMove,
+		})
+		if err != nil {
+			return game, turns, err
+		}
+
+		if earlyStop && game.settled() {
+			game.project()
+			break
+		}
+
+		if trace {
+			turns = append(turns, Turn{
+				Round:   len(turns) + 1,
+				A:       game.APrevious,
+				B:       game.BPrevious,
+				APoints: game.AScore - aScore,
+				BPoints: game.BScore - bScore,
+				AScore:  game.AScore,
+				BScore:  game.BScore,
+			})
+		}
+	}
+
+	game.AwardCooperationBonus()
+	return game, turns, nil
+}
+
+// LengthResult is how a game between two bots went at one length
+type LengthResult struct {
+	Rounds int
+	AScore int
+	BScore int
+	// AMean and BMean are the scores per scored round so different lengths
+	// can be compared
+	AMean float64
+	BMean float64
+}
+
+// Lead is how far A finished ahead of B, negative if B won
+func (r LengthResult) Lead() int {
+	return r.AScore - r.BScore
+}
+
+// LengthSweep plays a against b once at each of the lengths, with
+// everything else from opts, to show how the result changes with how long
+// the game is. Bots that know when the game ends, like
+// BackwardInductionBot, start defecting earlier relative to the length so
+// their edge is biggest in short games
+func LengthSweep(a, b Bot, lengths []int, opts GameOptions) ([]LengthResult, error) {
+	results := make([]LengthResult, 0, len(lengths))
+	for _, rounds := range lengths {
+		if rounds <= 0 {
+			return results, fmt.Errorf("game length %d: must be at least one round", rounds)
+		}
+		opts.Rounds = rounds
+		game, _, err := playGame(a, b, opts, false)
+		if err != nil {
+			return results, fmt.Errorf("%d rounds: %w", rounds, err)
+		}
+
+		scored := rounds - game.WarmupRounds
+		if scored < 1 {
+			scored = 1
+		}
+		results = append(results, LengthResult{
+			Rounds: rounds,
+			AScore: game.AScore,
+			BScore: game.BScore,
+			AMean:  float64(game.AScore) / float64(scored),
+			BMean:  float64(game.BScore) / float64(scored),
+		})
+	}
+	return results, nil
+}
+
+type ConfidenceOptions struct {
+	GameOptions
+
+	// Width is how narrow the 95% confidence interval of A's mean score per
+	// game has to be before we stop playing
+	Width float64
+	// MinGames is the fewest games to play before stopping, 2 if unset
+	MinGames int
+	// MaxGames caps the games played however wide the interval still is,
+	// 100,000 if unset
+	MaxGames int
+}
+
+type ConfidenceResult struct {
+	MatchupResult
+	Mean     float64 // A's mean score per game
+	Interval float64 // width of the 95% confidence interval around Mean
+}
+
+// PlayUntilConfident keeps playing games between a and b until A's mean
+// score is known to within opts.Width, so clear cut matchups stop early
+// while close random ones get as many games as they need
+func PlayUntilConfident(a, b Bot, opts ConfidenceOptions) ConfidenceResult {
+	minGames := opts.MinGames
+	if minGames < 2 {
+		minGames = 2
+	}
+	maxGames := opts.MaxGames
+	if maxGames <= 0 {
+		maxGames = 100_000
+	}
+
+	var result ConfidenceResult
+	sumSquares := 0.0 // of the differences from the mean, see Welford
+	for result.Games < maxGames {
+		game := PlayGame(a, b, opts.GameOptions)
+		result.add(game, TieDraw)
+		result.Games++
+
+		delta := float64(game.AScore) - result.Mean
+		result.Mean += delta / float64(result.Games)
+		sumSquares += delta * (float64(game.AScore) - result.Mean)
+
+		if result.Games < minGames {
+			continue
+		}
+		stdErr := math.Sqrt(sumSquares/float64(result.Games-1)) / math.Sqrt(float64(result.Games))
+		result.Interval = 2 * 1.96 * stdErr
+		if result.Interval <= opts.Width {
+			break
+		}
+	}
+
+	return result
+}
+
+// RecoveryTime plays a game between a and b with a's move in round at (from
+// 0) flipped to a defection by mistake, and returns how many rounds after
+// the mistake it took for the game to go back to how it would have gone
+// without it and stay there. It is -1 if the game never got back, like two
+// tit for tats echoing the defection back and forth. Both bots need to be
+// deterministic for the comparison to mean anything
+func RecoveryTime(a, b Bot, at int, opts GameOptions) int {
+	baseline := PlayGame(a, b, opts)
+	perturbed := PlayGame(mistakeBot{Bot: a, at: at}, b, opts)
+
+	recovered := len(baseline.AHistory)
+	for i := len(baseline.AHistory) - 1; i > at; i-- {
+		if perturbed.AHistory[i] != baseline.AHistory[i] || perturbed.BHistory[i] != baseline.BHistory[i] {
+			break
+		}
+		recovered = i
+	}
+	if recovered >= len(baseline.AHistory) {
+		return -1
+	}
+	return recovered - at
+}
+
+// mistakeBot plays as Bot except it defects in round at whatever Bot wanted
+type mistakeBot struct {
+	Bot
+	at int
+}
+
+func (r mistakeBot) Decision(state GameState) int {
+	move := r.Bot.Decision(state)
+	if len(state.bHistory) == r.at {
+		return Defect
+	}
+	return move
+}
+
+func (r mistakeBot) Reset() {
+	resetBot(r.Bot)
+}
@@ -0,0 +1,100 @@
+package main
+
+import "testing"
+
+func TestPlayUntilConfident(t *testing.T) {
+	random := func() Bot { return &RandomBot{SeededRand{Seed: 1}} }
+	tests := []struct {
+		name     string
+		a, b     Bot
+		opts     ConfidenceOptions
+		minGames int
+		maxGames int
+	}{
+		// no variance so the interval is closed as soon as it can be
+		{"deterministic", TitForTatBot{}, DefectBot{}, ConfidenceOptions{Width: 0.5}, 2, 2},
+		{"deterministic MinGames", TitForTatBot{}, DefectBot{}, ConfidenceOptions{Width: 0.5, MinGames: 10}, 10, 10},
+		// the first couple of games of coin flips can score the same by
+		// chance, so a few more have to be played first
+		{"coin flips", random(), CooperateBot{}, ConfidenceOptions{Width: 1, MinGames: 10}, 100, 100_000},
+		{"coin flips MaxGames", random(), CooperateBot{}, ConfidenceOptions{Width: 0.01, MinGames: 10, MaxGames: 50}, 50, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := PlayUntilConfident(tt.a, tt.b, tt.opts)
+			if result.Games < tt.minGames || result.Games > tt.maxGames {
+				t.Errorf("played %d games, want %d to %d", result.Games, tt.minGames, tt.maxGames)
+			}
+			if result.Games < tt.maxGames && result.Interval > tt.opts.Width {
+				t.Errorf("stopped with an interval of %v, wider than %v", result.Interval, tt.opts.Width)
+			}
+		})
+	}
+}
+
+func TestLengthSweep(t *testing.T) {
+	lengths := []int{5, 10, 20, 50}
+	tests := []struct {
+		name string
+		a, b Bot
+		lead int // how far A finishes ahead whatever the length
+	}{
+		{"cooperators", CooperateBot{}, TitForTatBot{}, 0},
+		// it takes the temptation once and both are punished after, which
+		// is worth the same 5 points in every game
+		{"backward induction", BackwardInductionBot{K: 3}, TitForTatBot{}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := LengthSweep(tt.a, tt.b, lengths, GameOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != len(lengths) {
+				t.Fatalf("got %d results, want %d", len(results), len(lengths))
+			}
+			for i, r := range results {
+				if r.Rounds != lengths[i] {
+					t.Errorf("result %d is for %d rounds, want %d", i, r.Rounds, lengths[i])
+				}
+				if r.Lead() != tt.lead {
+					t.Errorf("%d rounds: lead %d, want %d", r.Rounds, r.Lead(), tt.lead)
+				}
+				if tt.lead == 0 && (r.AMean != 1 || r.BMean != 1) {
+					t.Errorf("%d rounds: %v and %v per round, want the reward every round", r.Rounds, r.AMean, r.BMean)
+				}
+				// the same lead counts for more the shorter the game
+				if i > 0 && tt.lead != 0 && r.AMean-r.BMean >= results[i-1].AMean-results[i-1].BMean {
+					t.Errorf("%d rounds: edge per round %v didn't shrink from %v at %d rounds",
+						r.Rounds, r.AMean-r.BMean, results[i-1].AMean-results[i-1].BMean, results[i-1].Rounds)
+				}
+			}
+		})
+	}
+
+	if _, err := LengthSweep(CooperateBot{}, CooperateBot{}, []int{10, 0}, GameOptions{}); err == nil {
+		t.Error("swept a game with no rounds")
+	}
+}
+
+func TestRecoveryTime(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Bot
+		want int
+	}{
+		// the mistake echoes back and forth for the rest of the game
+		{"TitForTatBot", TitForTatBot{}, TitForTatBot{}, -1},
+		// takes its punishment and both cooperate again
+		{"ContriteTitForTatBot", ContriteTitForTatBot{}, ContriteTitForTatBot{}, 2},
+		{"ContriteTitForTatBot against TitForTatBot", ContriteTitForTatBot{}, TitForTatBot{}, 2},
+		{"CooperateBot", CooperateBot{}, CooperateBot{}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RecoveryTime(tt.a, tt.b, 3, GameOptions{Rounds: 20}); got != tt.want {
+				t.Errorf("RecoveryTime = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
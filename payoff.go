@@ -0,0 +1,92 @@
+package main
+
+// Payoff is the score each player gets for the four outcomes of a round
+type Payoff struct {
+	Reward     int `json:"reward"`     // both cooperate
+	Temptation int `json:"temptation"` // you defect and they cooperate
+	Sucker     int `json:"sucker"`     // you cooperate and they defect
+	Punishment int `json:"punishment"` // both defect
+}
+
+// DefaultPayoff is the matrix the game has always been played with
+var DefaultPayoff = Payoff{
+	Reward:     1,
+	Temptation: 3,
+	Sucker:     -2,
+	Punishment: -1,
+}
+
+// Score returns what players A and B get for playing a and b, moves that
+// are neither Cooperate nor Defect score nothing
+func (p Payoff) Score(a, b int) (int, int) {
+	switch {
+	case a == Cooperate && b == Cooperate:
+		return p.Reward, p.Reward
+	case a == Defect && b == Defect:
+		return p.Punishment, p.Punishment
+	case a == Cooperate && b == Defect:
+		return p.Sucker, p.Temptation
+	case a == Defect && b == Cooperate:
+		return p.Temptation, p.Sucker
+	}
+	return 0, 0
+}
+
+// Outcome is the pair of moves made by players A and B in a round
+type Outcome struct {
+	A int
+	B int
+}
+
+type PayoffAnalysis struct {
+	// Dilemma is true when T > R > P > S and 2R > T + S, so mutual
+	// cooperation beats taking turns exploiting each other
+	Dilemma bool
+	// Nash holds the pure strategy equilibria of a single round
+	Nash []Outcome
+	// Pareto is the outcome with the highest combined score, which no other
+	// outcome can improve on for both players at once
+	Pareto Outcome
+}
+
+// AnalyzePayoff checks a payoff matrix before it is used for an experiment,
+// for a valid dilemma Nash is mutual defection and Pareto mutual cooperation
+func AnalyzePayoff(p Payoff) PayoffAnalysis {
+	analysis := PayoffAnalysis{
+		Dilemma: p.Temptation > p.Reward &&
+			p.Reward > p.Punishment &&
+			p.Punishment > p.Sucker &&
+			2*p.Reward > p.Temptation+p.Sucker,
+	}
+
+	analysis.Pareto = Outcome{A: Cooperate, B: Cooperate}
+	best := 2 * p.Reward
+
+	moves := []int{Cooperate, Defect}
+	for _, a := range moves {
+		for _, b := range moves {
+			aScore, bScore := p.Score(a, b)
+
+			// nobody can do better by changing only their own move
+			switchA, _ := p.Score(otherMove(a), b)
+			_, switchB := p.Score(a, otherMove(b))
+			if aScore >= switchA && bScore >= switchB {
+				analysis.Nash = append(analysis.Nash, Outcome{A: a, B: b})
+			}
+
+			if aScore+bScore > best {
+				best = aScore + bScore
+				analysis.Pareto = Outcome{A: a, B: b}
+			}
+		}
+	}
+
+	return analysis
+}
+
+func otherMove(move int) int {
+	if move == Cooperate {
+		return Defect
+	}
+	return Cooperate
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzePayoff(t *testing.T) {
+	cc, dd := Outcome{Cooperate, Cooperate}, Outcome{Defect, Defect}
+	tests := []struct {
+		name   string
+		payoff Payoff
+		want   PayoffAnalysis
+	}{
+		{"default", DefaultPayoff, PayoffAnalysis{Dilemma: true, Nash: []Outcome{dd}, Pareto: cc}},
+		{"Axelrod", Payoff{Reward: 3, Temptation: 5, Sucker: 0, Punishment: 1}, PayoffAnalysis{Dilemma: true, Nash: []Outcome{dd}, Pareto: cc}},
+		// taking turns to exploit each other beats cooperating
+		{"alternation pays", Payoff{Reward: 2, Temptation: 5, Sucker: 0, Punishment: 1}, PayoffAnalysis{Nash: []Outcome{dd}, Pareto: Outcome{Cooperate, Defect}}},
+		{"stag hunt", Payoff{Reward: 4, Temptation: 3, Sucker: 0, Punishment: 1}, PayoffAnalysis{Nash: []Outcome{cc, dd}, Pareto: cc}},
+		{"harmony", Payoff{Reward: 3, Temptation: 2, Sucker: 1, Punishment: 0}, PayoffAnalysis{Nash: []Outcome{cc}, Pareto: cc}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AnalyzePayoff(tt.payoff); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AnalyzePayoff = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
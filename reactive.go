@@ -0,0 +1,69 @@
+package main
+
+import (
+	"github.com/sbinet/npyio/npy"
+	"gonum.org/v1/gonum/mat"
+	"os"
+)
+
+// ReactiveGrid is how well ReactiveBot does over a grid of its two
+// probabilities
+type ReactiveGrid struct {
+	P1     []float64
+	P2     []float64
+	Scores [][]float64 // mean score per game for P1[i] and P2[j] at [i][j]
+}
+
+// RunReactiveGrid plays a ReactiveBot for every pair of probabilities in
+// steps even steps from 0 to 1 against each opponent for games games, the
+// score is the mean per game over every opponent
+func RunReactiveGrid(steps int, opponents []Bot, games int, opts GameOptions) ReactiveGrid {
+	if steps < 2 {
+		steps = 2
+	}
+	probabilities := make([]float64, steps)
+	for i := range probabilities {
+		probabilities[i] = float64(i) / float64(steps-1)
+	}
+
+	grid := ReactiveGrid{
+		P1:     probabilities,
+		P2:     probabilities,
+		Scores: make([][]float64, steps),
+	}
+	for i, p1 := range grid.P1 {
+		grid.Scores[i] = make([]float64, steps)
+		for j, p2 := range grid.P2 {
+			bot := &ReactiveBot{P1: p1, P2: p2}
+			total := 0
+			for _, opponent := range opponents {
+				for g := 0; g < games; g++ {
+					total += PlayGame(bot, opponent, opts).AScore
+				}
+			}
+			if played := len(opponents) * games; played > 0 {
+				grid.Scores[i][j] = float64(total) / float64(played)
+			}
+		}
+	}
+	return grid
+}
+
+// Write saves the scores to path as a P1 by P2 .npy matrix
+func (g ReactiveGrid) Write(path string) error {
+	data := make([]float64, 0, len(g.P1)*len(g.P2))
+	for _, row := range g.Scores {
+		data = append(data, row...)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := npy.Write(file, mat.NewDense(len(g.P1), len(g.P2), data)); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/sbinet/npyio/npy"
+	"gonum.org/v1/gonum/mat"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunReactiveGrid(t *testing.T) {
+	tests := []struct {
+		name      string
+		opponents []Bot
+		corners   [2][2]float64 // P1 0 and 1 by P2 0 and 1
+	}{
+		// against cooperators only P1 matters, TFT gets R every round
+		{"cooperators", []Bot{CooperateBot{}}, [2][2]float64{{28, 28}, {10, 10}}},
+		{"cooperators and defectors", []Bot{CooperateBot{}, DefectBot{}}, [2][2]float64{{8.5, 4}, {-0.5, -5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grid := RunReactiveGrid(5, tt.opponents, 3, GameOptions{})
+			if len(grid.P1) != 5 || len(grid.P2) != 5 || len(grid.Scores) != 5 {
+				t.Fatalf("%d by %d grid with %d rows, want 5 by 5", len(grid.P1), len(grid.P2), len(grid.Scores))
+			}
+			for i, row := range grid.Scores {
+				if len(row) != 5 {
+					t.Fatalf("row %d has %d scores, want 5", i, len(row))
+				}
+			}
+			if grid.P1[0] != 0 || grid.P1[4] != 1 || grid.P2[2] != 0.5 {
+				t.Errorf("probabilities %v", grid.P1)
+			}
+			for i := 0; i < 2; i++ {
+				for j := 0; j < 2; j++ {
+					if got := grid.Scores[4*i][4*j]; got != tt.corners[i][j] {
+						t.Errorf("P1 %v P2 %v scored %v, want %v", grid.P1[4*i], grid.P2[4*j], got, tt.corners[i][j])
+					}
+				}
+			}
+
+			path := filepath.Join(t.TempDir(), "grid.npy")
+			if err := grid.Write(path); err != nil {
+				t.Fatal(err)
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			var m mat.Dense
+			if err := npy.Read(file, &m); err != nil {
+				t.Fatal(err)
+			}
+			if rows, cols := m.Dims(); rows != 5 || cols != 5 || m.At(4, 0) != grid.Scores[4][0] {
+				t.Errorf("read back a %dx%d matrix with TFT scoring %v", rows, cols, m.At(4, 0))
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// BestResponse works out the moves that score the most against opponent
+// over a whole game, playing as player A. The opponent has to be
+// deterministic and is assumed to decide on nothing but the round and the
+// last memory rounds, so each of those positions is only searched once. An
+// opponent caught playing differently in two positions that only differ
+// further back gives an error, but one that only shows it remembers more
+// later on can get past. A memory as long as the game is always right and
+// searches every position. The moves are returned as a MemoryNBot keyed by
+// the whole game so far
+func BestResponse(opponent Bot, opts GameOptions, memory int) (MemoryNBot, error) {
+	if memory < 0 {
+		return MemoryNBot{}, fmt.Errorf("can't remember %d rounds", memory)
+	}
+
+	resetBot(opponent)
+	game := NewGame(opts)
+	_ = game.Play(gameDecision{
+		aChoice: NoMove,
+		bChoice: NoMove,
+	})
+
+	search := bestResponseSearch{opponent: opponent, memory: memory, memo: map[string]bestMove{}}
+	if _, err := search.value(game); err != nil {
+		return MemoryNBot{}, err
+	}
+
+	// follow the best moves through the game to fill in the table
+	table := map[string]int{}
+	for !game.GameOver() {
+		move := search.memo[search.key(game)].move
+		table[historyKey(game.State().Swap(), game.Rounds)] = move
+		_ = game.Play(gameDecision{
+			aChoice: move,
+			bChoice: opponent.Decision(game.State()),
+		})
+	}
+	return MemoryNBot{N: game.Rounds, Table: table}, nil
+}
+
+type bestResponseSearch struct {
+	opponent Bot
+	memory   int
+	memo     map[string]bestMove
+}
+
+type bestMove struct {
+	move     int
+	value    int // points scored from here to the end of the game
+	opponent int // what the opponent plays here
+}
+
+func (s *bestResponseSearch) key(game Game) string {
+	return fmt.Sprintf("%d:%s", game.Round, historyKey(game.State().Swap(), s.memory))
+}
+
+// value is the most A can score over the rest of the game, cooperating when
+// both moves score the same
+func (s *bestResponseSearch) value(game Game) (int, error) {
+	if game.GameOver() {
+		return 0, nil
+	}
+	key := s.key(game)
+	opponentMove := s.opponent.Decision(game.State())
+	if best, ok := s.memo[key]; ok {
+		if best.opponent != opponentMove {
+			return 0, fmt.Errorf("%s looks back further than %d rounds, it plays differently in round %d after the same %s",
+				botType(s.opponent), s.memory, game.Round, historyKey(game.State().Swap(), s.memory))
+		}
+		return best.value, nil
+	}
+
+	best := bestMove{move: Cooperate, value: math.MinInt, opponent: opponentMove}
+	for _, move := range []int{Cooperate, Defect} {
+		// cap the histories so playing on doesn't write into the other branch
+		next := game
+		next.AHistory = game.AHistory[:len(game.AHistory):len(game.AHistory)]
+		next.BHistory = game.BHistory[:len(game.BHistory):len(game.BHistory)]
+		_ = next.Play(gameDecision{
+			aChoice: move,
+			bChoice: opponentMove,
+		})
+
+		rest, err := s.value(next)
+		if err != nil {
+			return 0, err
+		}
+		if value := next.AScore - game.AScore + rest; value > best.value {
+			best.move, best.value = move, value
+		}
+	}
+
+	s.memo[key] = best
+	return best.value, nil
+}
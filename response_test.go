@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBestResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		opponent func() Bot
+		rounds   int
+		memory   int
+		want     string
+	}{
+		{"CooperateBot", func() Bot { return CooperateBot{} }, 20, 0, strings.Repeat("D", 20)},
+		{"DefectBot", func() Bot { return DefectBot{} }, 20, 0, strings.Repeat("D", 20)},
+		// cooperating keeps the reward coming, only the last round is safe
+		// to defect in
+		{"ThresholdGrimBot", func() Bot { return ThresholdGrimBot{K: 1} }, 50, 1, strings.Repeat("C", 49) + "D"},
+		{"TitForTatBot", func() Bot { return TitForTatBot{} }, 50, 1, strings.Repeat("C", 49) + "D"},
+		// it only strikes back after the third defection, so the last three
+		// rounds are free, which takes remembering the whole game
+		{"ThresholdGrimBot K 3", func() Bot { return ThresholdGrimBot{K: 3} }, 12, 12, strings.Repeat("C", 9) + "DDD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := GameOptions{Rounds: tt.rounds}
+			response, err := BestResponse(tt.opponent(), opts, tt.memory)
+			if err != nil {
+				t.Fatal(err)
+			}
+			game := PlayGame(response, tt.opponent(), opts)
+			if got := movesString(game.AHistory); got != tt.want {
+				t.Errorf("best response played %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBestResponseIsBest checks the best response scores as much as the best
+// of every possible run of moves
+func TestBestResponseIsBest(t *testing.T) {
+	const rounds = 8
+	opponents := []struct {
+		name string
+		bot  func() Bot
+	}{
+		{"TitForTatBot", func() Bot { return TitForTatBot{} }},
+		{"ThresholdGrimBot", func() Bot { return ThresholdGrimBot{K: 1} }},
+		{"SlowTitForTatBot", func() Bot { return SlowTitForTatBot{} }},
+		{"HardTitForTatBot", func() Bot { return HardTitForTatBot{} }},
+		{"FirmButFairBot", func() Bot { return &FirmButFairBot{} }},
+		{"BackwardInductionBot", func() Bot { return BackwardInductionBot{K: 2} }},
+	}
+	for _, o := range opponents {
+		t.Run(o.name, func(t *testing.T) {
+			opts := GameOptions{Rounds: rounds}
+			best := 0
+			for i := 0; i < 1<<rounds; i++ {
+				bot := &ScriptedBot{}
+				for round := 0; round < rounds; round++ {
+					move := Cooperate
+					if i>>round&1 == 1 {
+						move = Defect
+					}
+					bot.Moves = append(bot.Moves, move)
+				}
+				if score := PlayGame(bot, o.bot(), opts).AScore; i == 0 || score > best {
+					best = score
+				}
+			}
+
+			response, err := BestResponse(o.bot(), opts, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			game := PlayGame(response, o.bot(), opts)
+			if game.AScore != best {
+				t.Errorf("best response scored %d playing %s, the best is %d", game.AScore, movesString(game.AHistory), best)
+			}
+		})
+	}
+}
+
+func TestBestResponseMemory(t *testing.T) {
+	opts := GameOptions{Rounds: 12}
+	// what hard tit for tat plays depends on the last three rounds
+	if _, err := BestResponse(HardTitForTatBot{}, opts, 1); err == nil {
+		t.Error("expected an error for an opponent looking back further than its memory")
+	}
+	if _, err := BestResponse(TitForTatBot{}, opts, -1); err == nil {
+		t.Error("expected an error for a negative memory")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Environment is one setting a tournament is run in for RankRobustness, like
+// a different number of rounds or payoff
+type Environment struct {
+	Name    string
+	Options TournamentOptions
+}
+
+// Robustness is how a strategy ranked in each environment, 1 being the
+// highest score, and its worst and mean rank over all of them
+type Robustness struct {
+	Name  string
+	Ranks []int // in the same order as the environments
+	Worst int
+	Mean  float64
+}
+
+// RankRobustness runs a tournament between the bots in every environment and
+// ranks them by their worst rank in any of them, then by their mean rank, so
+// the all-rounder that never does badly comes first ahead of a specialist
+// that wins some environments and flops in others
+func RankRobustness(ctx context.Context, bots map[string]Bot, environments []Environment) ([]Robustness, error) {
+	if len(environments) == 0 {
+		return nil, fmt.Errorf("%w: no environments", ErrNoOpponents)
+	}
+
+	names := sortedNames(bots)
+	robustness := make([]Robustness, len(names))
+	for i, name := range names {
+		robustness[i].Name = name
+	}
+
+	for _, env := range environments {
+		result, err := RunTournament(ctx, bots, env.Options)
+		if err != nil {
+			return nil, fmt.Errorf("environment %s: %w", env.Name, err)
+		}
+
+		ranks := rankByScore(result, names)
+		for i := range robustness {
+			rank := ranks[robustness[i].Name]
+			robustness[i].Ranks = append(robustness[i].Ranks, rank)
+			if rank > robustness[i].Worst {
+				robustness[i].Worst = rank
+			}
+			robustness[i].Mean += float64(rank) / float64(len(environments))
+		}
+	}
+
+	sort.SliceStable(robustness, func(i, j int) bool {
+		if robustness[i].Worst != robustness[j].Worst {
+			return robustness[i].Worst < robustness[j].Worst
+		}
+		return robustness[i].Mean < robustness[j].Mean
+	})
+	return robustness, nil
+}
+
+// rankByScore ranks the bots by their score in the tournament, bots on the
+// same score share a rank and the next one down skips the places they took
+func rankByScore(result TournamentResult, names []string) map[string]int {
+	scores := make(map[string]int, len(names))
+	for _, name := range names {
+		scores[name] = result.Standing(name).Score
+	}
+
+	ordered := append([]string(nil), names...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return scores[ordered[i]] > scores[ordered[j]]
+	})
+
+	ranks := make(map[string]int, len(ordered))
+	for i, name := range ordered {
+		if i > 0 && scores[name] == scores[ordered[i-1]] {
+			ranks[name] = ranks[ordered[i-1]]
+			continue
+		}
+		ranks[name] = i + 1
+	}
+	return ranks
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRankRobustness(t *testing.T) {
+	// cooperating pays more here, so tit for tat beats always defecting
+	generous := Payoff{Temptation: 3, Reward: 2, Punishment: -1, Sucker: -2}
+	classic := map[string]Bot{"CooperateBot": CooperateBot{}, "DefectBot": DefectBot{}, "TitForTatBot": TitForTatBot{}}
+	tests := []struct {
+		name         string
+		bots         map[string]Bot
+		environments []Environment
+		want         []Robustness
+	}{
+		{
+			// always defecting scores n+4 as player A, tit for tat n-1 and
+			// always cooperating 0, however long the game
+			"winning both",
+			classic,
+			[]Environment{
+				{"short", TournamentOptions{Games: 1}},
+				{"long", TournamentOptions{Games: 1, GameOptions: GameOptions{Rounds: 200}}},
+			},
+			[]Robustness{
+				{"DefectBot", []int{1, 1}, 1, 1},
+				{"TitForTatBot", []int{2, 2}, 2, 2},
+				{"CooperateBot", []int{3, 3}, 3, 3},
+			},
+		},
+		{
+			"never last beats winning one",
+			classic,
+			[]Environment{
+				{"classic", TournamentOptions{Games: 1}},
+				{"generous", TournamentOptions{Games: 1, GameOptions: GameOptions{Payoff: &generous}}},
+			},
+			[]Robustness{
+				{"TitForTatBot", []int{2, 1}, 2, 1.5},
+				{"DefectBot", []int{1, 3}, 3, 2},
+				{"CooperateBot", []int{3, 2}, 3, 2.5},
+			},
+		},
+		{
+			// both cooperate throughout and score the same
+			"shared rank",
+			map[string]Bot{"CooperateBot": CooperateBot{}, "TitForTatBot": TitForTatBot{}},
+			[]Environment{{"classic", TournamentOptions{Games: 1}}},
+			[]Robustness{
+				{"CooperateBot", []int{1}, 1, 1},
+				{"TitForTatBot", []int{1}, 1, 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RankRobustness(context.Background(), tt.bots, tt.environments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := RankRobustness(context.Background(), classic, nil); !errors.Is(err, ErrNoOpponents) {
+		t.Errorf("no environments gave %v, want ErrNoOpponents", err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import "fmt"
+
+// Instance is one member of a population, several members can play the same
+// strategy and each keeps its own score
+type Instance struct {
+	Name string
+	Bot  Bot
+}
+
+// InstanceResult is how one member of the population did in a round robin
+type InstanceResult struct {
+	Name  string
+	Games int
+	Byes  int // rounds it sat out because the population was odd
+	Score int
+}
+
+// bye stands in for the missing player when the population is odd, whoever
+// is paired with it sits the round out
+const bye = -1
+
+// RoundRobinSchedule pairs up n players by index so every player meets every
+// other exactly once, a round at a time with nobody playing twice in a
+// round. With an odd n there are n rounds and each player gets one bye,
+// which shows up as a pairing with -1, otherwise there are n-1 rounds
+func RoundRobinSchedule(n int) [][][2]int {
+	players := make([]int, n)
+	for i := range players {
+		players[i] = i
+	}
+	if n%2 == 1 {
+		players = append(players, bye)
+	}
+
+	// the circle method, the first player stays put while everyone else
+	// moves round one place each round
+	m := len(players)
+	var rounds [][][2]int
+	for r := 0; r < m-1; r++ {
+		round := make([][2]int, 0, m/2)
+		for i := 0; i < m/2; i++ {
+			round = append(round, [2]int{players[i], players[m-1-i]})
+		}
+		rounds = append(rounds, round)
+
+		last := players[m-1]
+		copy(players[2:], players[1:m-1])
+		players[1] = last
+	}
+	return rounds
+}
+
+// RunRoundRobin plays one game between every two members of the population
+// following RoundRobinSchedule, with whoever comes first in the population
+// as player A. Results are in the same order as the population
+func RunRoundRobin(population []Instance, opts GameOptions) ([]InstanceResult, error) {
+	if len(population) < 2 {
+		return nil, ErrNoOpponents
+	}
+
+	results := make([]InstanceResult, len(population))
+	for i, instance := range population {
+		results[i].Name = instance.Name
+	}
+
+	for _, round := range RoundRobinSchedule(len(population)) {
+		for _, pair := range round {
+			a, b := pair[0], pair[1]
+			if a == bye || b == bye {
+				if a == bye {
+					a = b
+				}
+				results[a].Byes++
+				continue
+			}
+			if b < a {
+				a, b = b, a
+			}
+
+			game, _, err := playGame(population[a].Bot, population[b].Bot, opts, false)
+			if err != nil {
+				return results, fmt.Errorf("%s (%d) against %s (%d): %w",
+					population[a].Name, a, population[b].Name, b, err)
+			}
+			results[a].Games++
+			results[a].Score += game.AScore
+			results[b].Games++
+			results[b].Score += game.BScore
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRoundRobinSchedule(t *testing.T) {
+	for n := 2; n <= 9; n++ {
+		schedule := RoundRobinSchedule(n)
+		wantRounds := n - 1
+		if n%2 == 1 {
+			wantRounds = n
+		}
+		if len(schedule) != wantRounds {
+			t.Errorf("%d players got %d rounds, want %d", n, len(schedule), wantRounds)
+		}
+
+		met := map[[2]int]int{}
+		byes := make([]int, n)
+		for r, round := range schedule {
+			playing := map[int]bool{}
+			for _, pair := range round {
+				for _, player := range pair {
+					if player == bye {
+						continue
+					}
+					if playing[player] {
+						t.Errorf("%d players: player %d plays twice in round %d", n, player, r)
+					}
+					playing[player] = true
+				}
+				a, b := pair[0], pair[1]
+				switch {
+				case a == bye:
+					byes[b]++
+				case b == bye:
+					byes[a]++
+				default:
+					if b < a {
+						a, b = b, a
+					}
+					met[[2]int{a, b}]++
+				}
+			}
+		}
+
+		for a := 0; a < n; a++ {
+			for b := a + 1; b < n; b++ {
+				if met[[2]int{a, b}] != 1 {
+					t.Errorf("%d players: %d and %d met %d times", n, a, b, met[[2]int{a, b}])
+				}
+			}
+			if want := n % 2; byes[a] != want {
+				t.Errorf("%d players: player %d had %d byes, want %d", n, a, byes[a], want)
+			}
+		}
+	}
+}
+
+func TestRunRoundRobin(t *testing.T) {
+	tests := []struct {
+		name       string
+		population []Instance
+		want       []InstanceResult
+	}{
+		{
+			// each tit for tat gets 10 from the other two and loses 11 to
+			// DefectBot, which takes 3 from each and then loses 9 rounds
+			"three of the same and a defector",
+			[]Instance{{"TitForTatBot", TitForTatBot{}}, {"TitForTatBot", TitForTatBot{}}, {"TitForTatBot", TitForTatBot{}}, {"DefectBot", DefectBot{}}},
+			[]InstanceResult{{"TitForTatBot", 3, 0, 9}, {"TitForTatBot", 3, 0, 9}, {"TitForTatBot", 3, 0, 9}, {"DefectBot", 3, 0, -18}},
+		},
+		{
+			"three of the same alone",
+			[]Instance{{"ThresholdGrimBot", ThresholdGrimBot{K: 1}}, {"ThresholdGrimBot", ThresholdGrimBot{K: 1}}, {"ThresholdGrimBot", ThresholdGrimBot{K: 1}}},
+			[]InstanceResult{{"ThresholdGrimBot", 2, 1, 20}, {"ThresholdGrimBot", 2, 1, 20}, {"ThresholdGrimBot", 2, 1, 20}},
+		},
+		{
+			// the same strategy playing different moves scores differently
+			"same name different play",
+			[]Instance{{"ScriptedBot", scripted("C")}, {"ScriptedBot", scripted("D")}, {"ScriptedBot", scripted("CD")}},
+			[]InstanceResult{{"ScriptedBot", 2, 1, -20 + 1 - 18}, {"ScriptedBot", 2, 1, 30 + 3 - 9}, {"ScriptedBot", 2, 1, 28 - 11}},
+		},
+		{
+			"odd with a cooperator",
+			[]Instance{{"TitForTatBot", TitForTatBot{}}, {"TitForTatBot", TitForTatBot{}}, {"TitForTatBot", TitForTatBot{}}, {"DefectBot", DefectBot{}}, {"CooperateBot", CooperateBot{}}},
+			[]InstanceResult{{"TitForTatBot", 4, 1, 19}, {"TitForTatBot", 4, 1, 19}, {"TitForTatBot", 4, 1, 19}, {"DefectBot", 4, 1, 12}, {"CooperateBot", 4, 1, 10}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RunRoundRobin(tt.population, GameOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := RunRoundRobin([]Instance{{"TitForTatBot", TitForTatBot{}}}, GameOptions{}); !errors.Is(err, ErrNoOpponents) {
+		t.Errorf("one player gave %v, want ErrNoOpponents", err)
+	}
+}
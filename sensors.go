@@ -0,0 +1,135 @@
+package main
+
+import "github.com/yaricom/goNEAT/v2/neat/network"
+
+// SensorSet picks which inputs a network gets each round. The same set
+// decides how many inputs a new genome starts with and what is loaded into
+// them, so the two can't drift apart. Inputs are always loaded in the order
+// the flags are declared
+type SensorSet uint
+
+const (
+	// SensePreviousMoves is its own and the opponent's move last round
+	SensePreviousMoves SensorSet = 1 << iota
+	// SenseRound is how far through the game it is, from 0 to 1
+	SenseRound
+	// SenseRoundsLeft is how many rounds are left over how many there are
+	SenseRoundsLeft
+	// SenseScoreDifference is how far ahead or behind the opponent it is
+	SenseScoreDifference
+	// SenseHistory is its own and the opponent's moves over the last
+	// historyWindow rounds, most recent first, NoMove before the game
+	// started
+	SenseHistory
+)
+
+// DefaultSensors are what networks were trained on before there was a choice
+const DefaultSensors = SensePreviousMoves
+
+// historyWindow is how many rounds SenseHistory looks back on
+const historyWindow = 4
+
+// orDefault is the set itself, or DefaultSensors if none are picked
+func (s SensorSet) orDefault() SensorSet {
+	if s == 0 {
+		return DefaultSensors
+	}
+	return s
+}
+
+// Inputs is how many values Values returns, which is how many inputs a
+// genome for the set needs
+func (s SensorSet) Inputs() int {
+	s = s.orDefault()
+	inputs := 0
+	if s&SensePreviousMoves != 0 {
+		inputs += 2
+	}
+	if s&SenseRound != 0 {
+		inputs++
+	}
+	if s&SenseRoundsLeft != 0 {
+		inputs++
+	}
+	if s&SenseScoreDifference != 0 {
+		inputs++
+	}
+	if s&SenseHistory != 0 {
+		inputs += 2 * historyWindow
+	}
+	return inputs
+}
+
+// Values are the inputs a network gets with state seen from its side. The
+// network was trained as player A so it expects its own moves first, then
+// the opponent's
+func (s SensorSet) Values(state GameState) []float64 {
+	s = s.orDefault()
+	values := make([]float64, 0, s.Inputs())
+	if s&SensePreviousMoves != 0 {
+		values = append(values, float64(state.bPrevious), float64(state.aPrevious))
+	}
+	if s&SenseRound != 0 {
+		progress := 0.0
+		if state.rounds > 0 {
+			progress = float64(state.round) / float64(state.rounds)
+		}
+		values = append(values, progress)
+	}
+	if s&SenseRoundsLeft != 0 {
+		left := 0.0
+		if state.rounds > 0 {
+			left = float64(state.RoundsLeft()) / float64(state.rounds)
+		}
+		values = append(values, left)
+	}
+	if s&SenseScoreDifference != 0 {
+		// divided by the most it could be after this many rounds so it
+		// stays between -1 and 1
+		difference := 0.0
+		swing := state.bPayoff.Temptation - state.bPayoff.Sucker
+		if rounds := len(state.bHistory); rounds > 0 && swing > 0 {
+			own, opponent := scores(state)
+			difference = float64(own-opponent) / float64(rounds*swing)
+		}
+		values = append(values, difference)
+	}
+	if s&SenseHistory != 0 {
+		values = appendHistory(values, state.bHistory)
+		values = appendHistory(values, state.aHistory)
+	}
+	return values
+}
+
+// appendHistory adds the last historyWindow moves, most recent first
+func appendHistory(values []float64, history []int) []float64 {
+	for i := 1; i <= historyWindow; i++ {
+		move := NoMove
+		if len(history) >= i {
+			move = history[len(history)-i]
+		}
+		values = append(values, float64(move))
+	}
+	return values
+}
+
+// guessSensors works out the set a network was trained on from how many
+// inputs it has, which only tells the sets networks were trained on before
+// SensorSet apart
+func guessSensors(net *network.Network) SensorSet {
+	if sensorCount(net) == (DefaultSensors | SenseScoreDifference).Inputs() {
+		return DefaultSensors | SenseScoreDifference
+	}
+	return DefaultSensors
+}
+
+// sensorCount is how many inputs the network takes, counting the bias
+func sensorCount(net *network.Network) int {
+	count := 0
+	for _, node := range net.AllNodes() {
+		if node.IsSensor() {
+			count++
+		}
+	}
+	return count
+}
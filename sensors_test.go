@@ -0,0 +1,110 @@
+package main
+
+import (
+	"github.com/yaricom/goNEAT/v2/neat/genetics"
+	"math"
+	"testing"
+)
+
+func TestScoreDifferenceSensor(t *testing.T) {
+	modest := Payoff{Temptation: 2, Reward: 1, Punishment: -1, Sucker: -2}
+	tests := []struct {
+		name  string
+		a, b  string
+		opts  GameOptions
+		aWant float64 // as seen by A, which gets the state swapped
+		bWant float64
+	}{
+		{"first round", "", "", GameOptions{}, 0, 0},
+		// 4 to -1 after two rounds that could have swung 5 each
+		{"ahead", "CD", "CC", GameOptions{}, 0.5, -0.5},
+		{"level", "CDDC", "CCDD", GameOptions{}, 0, 0},
+		{"as far ahead as it can be", "DDD", "CCC", GameOptions{}, 1, -1},
+		// B scores -1 from its own payoff, which can only swing 4 a round
+		{"own payoff", "CD", "CC", GameOptions{BPayoff: &modest}, 0.5, -0.625},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := NewGame(tt.opts)
+			_ = game.Play(gameDecision{aChoice: NoMove, bChoice: NoMove})
+			a, b := moves(tt.a), moves(tt.b)
+			for i := range a {
+				if err := game.Play(gameDecision{aChoice: a[i], bChoice: b[i]}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			sensors := DefaultSensors | SenseScoreDifference
+			aValues := sensors.Values(game.State().Swap())
+			bValues := sensors.Values(game.State())
+			if len(aValues) != sensors.Inputs() {
+				t.Fatalf("got %d values, want %d", len(aValues), sensors.Inputs())
+			}
+			// it comes after the previous moves
+			if got := aValues[2]; math.Abs(got-tt.aWant) > 1e-9 {
+				t.Errorf("A senses %v, want %v", got, tt.aWant)
+			}
+			if got := bValues[2]; math.Abs(got-tt.bWant) > 1e-9 {
+				t.Errorf("B senses %v, want %v", got, tt.bWant)
+			}
+		})
+	}
+}
+
+func TestSensorSetInputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		sensors SensorSet
+		want    int
+	}{
+		{"default", 0, 2},
+		{"previous moves", SensePreviousMoves, 2},
+		{"round", SenseRound, 1},
+		{"score difference", DefaultSensors | SenseScoreDifference, 3},
+		{"timing", SensePreviousMoves | SenseRound | SenseRoundsLeft, 4},
+		{"history", SenseHistory, 2 * historyWindow},
+		{"everything", SensePreviousMoves | SenseRound | SenseRoundsLeft | SenseScoreDifference | SenseHistory, 5 + 2*historyWindow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sensors.Inputs(); got != tt.want {
+				t.Errorf("Inputs = %d, want %d", got, tt.want)
+			}
+
+			game := NewGame(GameOptions{})
+			_ = game.Play(gameDecision{aChoice: NoMove, bChoice: NoMove})
+			_ = game.Play(gameDecision{aChoice: Cooperate, bChoice: Defect})
+			if got := len(tt.sensors.Values(game.State())); got != tt.want {
+				t.Errorf("got %d values, want %d", got, tt.want)
+			}
+
+			// built the way training builds the first genome, the network
+			// takes every value and plays a whole game on them. Fully linked
+			// so the output can't end up cut off from the inputs
+			genome := genetics.NewGenomeRand(0, tt.sensors.Inputs(), 1, 1, 10, false, 1)
+			organism, err := genetics.NewOrganism(0, genome, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sensorCount(organism.Phenotype); got != tt.want {
+				t.Errorf("genome has %d inputs, want %d", got, tt.want)
+			}
+			if _, err := playOrganism(organism, TitForTatBot{}, GameOptions{}, tt.sensors); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestGuessSensors(t *testing.T) {
+	for _, sensors := range []SensorSet{DefaultSensors, DefaultSensors | SenseScoreDifference} {
+		genome := genetics.NewGenomeRand(0, sensors.Inputs(), 1, 1, 10, false, 1)
+		net, err := genome.Genesis(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := guessSensors(net); got != sensors {
+			t.Errorf("guessed %b for a network built for %b", got, sensors)
+		}
+	}
+}
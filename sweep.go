@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/yaricom/goNEAT/v2/experiment"
+	"github.com/yaricom/goNEAT/v2/neat"
+	"github.com/yaricom/goNEAT/v2/neat/genetics"
+	exprand "golang.org/x/exp/rand"
+	"io"
+	"math"
+	"math/rand"
+	"text/tabwriter"
+)
+
+// SweepResult is how a single NEAT training run went for one seed
+type SweepResult struct {
+	Seed       int64
+	Fitness    float64 // fitness of the best organism found
+	Complexity int     // nodes plus links in the best organism's network
+	Solved     bool
+}
+
+// train runs a NEAT experiment seeded with seed. goNEAT draws from the
+// math/rand global source and the bots from the golang.org/x/exp/rand one,
+// so both get seeded to make the run repeatable. Opponents with their own
+// random source would carry on from the last run, so they are reseeded too
+func train(ctx context.Context, options *neat.Options, evaluator PrisonersDilemmaGenerationEvaluator, seed int64) (*experiment.Experiment, error) {
+	rand.Seed(seed)
+	exprand.Seed(uint64(seed))
+	for _, opponent := range evaluator.opponents() {
+		reseedBot(opponent.Bot, exprand.Uint64())
+	}
+	if evaluator.Context == nil {
+		evaluator.Context = ctx
+	}
+
+	exp := &experiment.Experiment{
+		Id:       0,
+		Trials:   make(experiment.Trials, options.NumRuns),
+		RandSeed: seed,
+	}
+
+	// This special constructor creates a Genome with in inputs, out outputs, n out of maxHidden hidden units, and random
+	// connectivity.  If rec is true then recurrent connections will be included. The last input is a bias
+	// link_prob is the probability of a link. The created genome is not modular.
+	// newId, in, out, n, maxHidden int, recurrent bool, linkProb float64
+	genomeRand := genetics.NewGenomeRand(0, evaluator.Sensors.Inputs(), 1, 1, 10, false, 0.7)
+
+	err := exp.Execute(neat.NewContext(ctx, options), genomeRand, evaluator, nil)
+	return exp, err
+}
+
+// SeedSweep trains once per seed so you can see how much the champion
+// depends on where training started rather than on the setup. Each seed
+// gets its own experiment with options.NumRuns trials and the best organism
+// over all of them is reported
+func SeedSweep(ctx context.Context, options *neat.Options, evaluator PrisonersDilemmaGenerationEvaluator, seeds []int64) ([]SweepResult, error) {
+	results := make([]SweepResult, 0, len(seeds))
+	for _, seed := range seeds {
+		exp, err := train(ctx, options, evaluator, seed)
+		if err != nil {
+			return results, fmt.Errorf("seed %d: %w", seed, err)
+		}
+
+		result := SweepResult{Seed: seed, Solved: exp.Solved()}
+		if org, _, ok := exp.BestOrganism(false); ok {
+			result.Fitness = org.Fitness
+			result.Complexity = org.Phenotype.Complexity()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// PrintSweep writes a row per seed followed by the mean and standard
+// deviation of the champion fitness and complexity
+func PrintSweep(w io.Writer, results []SweepResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "seed\tfitness\tcomplexity\tsolved")
+	fitness := make([]float64, 0, len(results))
+	complexity := make([]float64, 0, len(results))
+	for _, r := range results {
+		fmt.Fprintf(tw, "%d\t%.3f\t%d\t%t\n", r.Seed, r.Fitness, r.Complexity, r.Solved)
+		fitness = append(fitness, r.Fitness)
+		complexity = append(complexity, float64(r.Complexity))
+	}
+
+	mean, sd := meanStdDev(fitness)
+	fmt.Fprintf(tw, "fitness\tmean %.3f\tsd %.3f\t\n", mean, sd)
+	mean, sd = meanStdDev(complexity)
+	fmt.Fprintf(tw, "complexity\tmean %.3f\tsd %.3f\t\n", mean, sd)
+	return tw.Flush()
+}
+
+func meanStdDev(xs []float64) (float64, float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	mean := 0.0
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+
+	variance := 0.0
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	if len(xs) > 1 {
+		variance /= float64(len(xs) - 1)
+	}
+	return mean, math.Sqrt(variance)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"github.com/yaricom/goNEAT/v2/neat"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestSeedSweepRepeatable(t *testing.T) {
+	configFile, err := os.Open("xor.neat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options, err := neat.LoadNeatOptions(configFile)
+	configFile.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	options.PopSize = 20
+	options.NumRuns = 1
+	options.NumGenerations = 3
+
+	// training saves the best genome to the working directory
+	chdirTemp(t)
+
+	evaluator := PrisonersDilemmaGenerationEvaluator{
+		Opponents: []Opponent{{Bot: TitForTatBot{}}, {Bot: &RandomBot{}}},
+		Logger:    NewLogger(io.Discard, LogQuiet),
+	}
+	seeds := []int64{3, 3, 8}
+	results, err := SeedSweep(context.Background(), options, evaluator, seeds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(seeds) {
+		t.Fatalf("%d results for %d seeds", len(results), len(seeds))
+	}
+	if results[0] != results[1] {
+		t.Errorf("the same seed gave %+v then %+v", results[0], results[1])
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/sbinet/npyio/npz"
+	"io"
+	"strings"
+)
+
+// TableBot plays from a table of how likely it is to cooperate after each
+// possible run of the last N rounds. Rounds are read oldest first with the
+// bot's own move then the opponent's, C as 0 and D as 1, making the index a
+// binary number, so a memory one table is ordered CC, CD, DC, DD like
+// ExtractMemoryOneTable. It cooperates until N rounds have been played
+type TableBot struct {
+	N     int
+	Table []float64 // 4^N probabilities of cooperating
+	SeededRand
+}
+
+// NewTableBot works out N from the size of table, which has to be a power
+// of four
+func NewTableBot(table []float64) (*TableBot, error) {
+	n := 0
+	for size := 1; size < len(table); size *= 4 {
+		n++
+	}
+	if len(table) == 0 || 1<<(2*n) != len(table) {
+		return nil, fmt.Errorf("table has %d entries, it needs a power of four", len(table))
+	}
+	for i, p := range table {
+		if p < 0 || p > 1 {
+			return nil, fmt.Errorf("table entry %d is %v, it needs to be a probability", i, p)
+		}
+	}
+	return &TableBot{N: n, Table: table}, nil
+}
+
+// LoadTableBot reads the table stored under key in the .npz archive at path,
+// such as one saved from NumPy with numpy.savez
+func LoadTableBot(path, key string) (*TableBot, error) {
+	f, err := npz.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var table []float64
+	if err := f.Read(key, &table); err != nil {
+		return nil, fmt.Errorf("reading %s from %s: %w", key, path, err)
+	}
+	return NewTableBot(table)
+}
+
+// LoadTableBotCSV reads a MemoryNBot from CSV rows of history_key,move, like
+// "CD,D" to defect after cooperating while the opponent defected. A header
+// row of history_key,move is skipped. Every key is a run of the same number
+// of rounds written as C and D, which sets N, so the bot cooperates until N
+// rounds have been played. A key can only be given once. Errors name the row
+// of the file they are in
+func LoadTableBotCSV(r io.Reader) (MemoryNBot, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return MemoryNBot{}, fmt.Errorf("reading table: %w", err)
+	}
+	first := 1
+	if len(rows) > 0 && rows[0][0] == "history_key" && rows[0][1] == "move" {
+		rows = rows[1:]
+		first++
+	}
+
+	bot := MemoryNBot{Table: map[string]int{}}
+	seen := map[string]int{}
+	for i, row := range rows {
+		line := i + first
+		key, move := strings.ToUpper(row[0]), strings.ToUpper(row[1])
+		if key == "" || len(key)%2 != 0 || strings.Trim(key, "CD") != "" {
+			return MemoryNBot{}, fmt.Errorf("row %d: key %q needs to be pairs of C and D", line, row[0])
+		}
+		if i == 0 {
+			bot.N = len(key) / 2
+		} else if len(key) != 2*bot.N {
+			return MemoryNBot{}, fmt.Errorf("row %d: key %q is %d rounds, the first key is %d", line, row[0], len(key)/2, bot.N)
+		}
+		if earlier, ok := seen[key]; ok {
+			return MemoryNBot{}, fmt.Errorf("row %d: key %q is already on row %d", line, row[0], earlier)
+		}
+		seen[key] = line
+		switch move {
+		case "C":
+			bot.Table[key] = Cooperate
+		case "D":
+			bot.Table[key] = Defect
+		default:
+			return MemoryNBot{}, fmt.Errorf("row %d: %w: %q", line, ErrInvalidMove, row[1])
+		}
+	}
+	if len(bot.Table) == 0 {
+		return MemoryNBot{}, errors.New("table has no rows")
+	}
+	return bot, nil
+}
+
+func (r *TableBot) Decision(state GameState) int {
+	n := len(state.bHistory)
+	if n < r.N || len(r.Table) != 1<<(2*r.N) {
+		return Cooperate
+	}
+
+	index := 0
+	for i := n - r.N; i < n; i++ {
+		index = index<<2 | state.bHistory[i]<<1 | state.aHistory[i]
+	}
+
+	if r.Float64() < r.Table[index] {
+		return Cooperate
+	}
+	return Defect
+}
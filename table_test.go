@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"github.com/sbinet/npyio/npz"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTableBot(t *testing.T) {
+	// tit for two tats defects when the opponent defected in both of the
+	// last two rounds, the opponent's moves being bits 2 and 0 of the index
+	tf2t := make([]float64, 16)
+	for i := range tf2t {
+		if i&0b0101 != 0b0101 {
+			tf2t[i] = 1
+		}
+	}
+	path := filepath.Join(t.TempDir(), "tables.npz")
+	err := npz.Write(path, map[string]interface{}{
+		"tft":   []float64{1, 0, 1, 0},
+		"tf2t":  tf2t,
+		"odd":   []float64{1, 0, 1},
+		"wrong": []float64{1, 2, 1, 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want string // moves against CDDCDCDDDC
+		err  bool
+	}{
+		{"tft", "CCDDCDCDDD", false},
+		{"tf2t", "CCCDCCCCDD", false},
+		{"odd", "", true},
+		{"wrong", "", true},
+		{"missing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			bot, err := LoadTableBot(path, tt.key)
+			if tt.err {
+				if err == nil {
+					t.Error("loaded without an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opponent := "CDDCDCDDDC"
+			game := PlayGame(scripted(opponent), bot, GameOptions{Rounds: len(opponent)})
+			if got := movesString(game.BHistory); got != tt.want {
+				t.Errorf("played %s against %s, want %s", got, opponent, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadTableBot(filepath.Join(t.TempDir(), "missing.npz"), "tft"); err == nil {
+		t.Error("loaded a missing file")
+	}
+}
+
+func TestLoadTableBotCSV(t *testing.T) {
+	tft := "history_key,move\nCC,C\nCD,D\nDC,C\nDD,D\n"
+	bot, err := LoadTableBotCSV(strings.NewReader(tft))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opponent := "CCDCDDCCDC"
+	game := PlayGame(scripted(opponent), bot, GameOptions{Rounds: len(opponent)})
+	// tit for tat copies the opponent a round later
+	if got, want := movesString(game.BHistory), "C"+opponent[:len(opponent)-1]; got != want {
+		t.Errorf("played %s against %s, want %s", got, opponent, want)
+	}
+}
+
+func TestLoadTableBotCSVErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		csv   string
+		want  string
+		isErr error
+	}{
+		{"odd key", "history_key,move\nCC,C\nCDC,D\n", "row 3", nil},
+		{"not C or D", "CC,C\nCX,D\n", "row 2", nil},
+		{"empty key", ",C\n", "row 1", nil},
+		{"longer key", "CC,C\nCD,D\nCCDD,C\n", `row 3: key "CCDD" is 2 rounds, the first key is 1`, nil},
+		{"shorter key", "history_key,move\nCCCC,C\nCC,D\n", `row 3: key "CC" is 1 rounds, the first key is 2`, nil},
+		{"bad move", "CC,C\nCD,X\n", "row 2", ErrInvalidMove},
+		{"duplicate key", "history_key,move\nCC,C\nCD,D\nCC,D\n", `row 4: key "CC" is already on row 2`, nil},
+		{"duplicate in another case", "CC,C\ncc,D\n", `row 2: key "cc" is already on row 1`, nil},
+		{"missing move", "CC\n", "reading table", nil},
+		{"no rows", "history_key,move\n", "no rows", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTableBotCSV(strings.NewReader(tt.csv))
+			if err == nil {
+				t.Fatal("loaded without an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q doesn't mention %q", err, tt.want)
+			}
+			if tt.isErr != nil && !errors.Is(err, tt.isErr) {
+				t.Errorf("error %q isn't %v", err, tt.isErr)
+			}
+		})
+	}
+}
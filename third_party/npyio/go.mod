@@ -0,0 +1,8 @@
+module github.com/sbinet/npyio
+
+go 1.14
+
+require (
+	github.com/campoy/embedmd v1.0.0
+	gonum.org/v1/gonum v0.8.2
+)
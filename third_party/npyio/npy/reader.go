@@ -0,0 +1,1038 @@
+// Copyright 2016 The npyio Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package npy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Read reads the data from the r NumPy data file io.Reader, into the
+// provided pointed at value ptr.
+// Read returns an error if the on-disk data type and the one provided
+// don't match.
+//
+// If a *mat.Dense matrix is passed to Read, the numpy-array data is loaded
+// into the Dense matrix, honouring Fortran/C-order and dimensions/shape
+// parameters.
+//
+// Only numpy-arrays with up to 2 dimensions can be loaded into a Dense
+// matrix; only numpy-arrays with elements convertible to float64 are
+// supported.
+//
+// Numpy-arrays with any number of dimensions may be read into a flat
+// slice, in their on-disk order. The full shape and order are available
+// from Reader.Header so callers can reshape the data themselves.
+// C-ordered numpy-arrays may also be read into nested Go arrays whose
+// dimensions match the on-disk shape (e.g. [2][2][2]float64).
+//
+// Signed (resp. unsigned) integer data of any width may be read into
+// int and []int (resp. uint and []uint) values, provided each element
+// fits in the platform-sized integer.
+func Read(r io.Reader, ptr interface{}) error {
+	rr, err := NewReader(r)
+	if err != nil {
+		return err
+	}
+
+	return rr.Read(ptr)
+}
+
+// Reader reads data from a NumPy data file.
+type Reader struct {
+	r   io.Reader
+	err error // last error
+
+	Header Header
+	order  binary.ByteOrder
+}
+
+// NewReader creates a new NumPy data file format reader.
+func NewReader(r io.Reader) (*Reader, error) {
+	rr := &Reader{r: r}
+	rr.readHeader()
+	if rr.err != nil {
+		return nil, rr.err
+	}
+	return rr, rr.err
+}
+
+func (r *Reader) readHeader() {
+	if r.err != nil {
+		return
+	}
+	r.order = binary.LittleEndian
+	var magic [6]byte
+	r.read(&magic)
+	if r.err != nil {
+		return
+	}
+	if magic != Magic {
+		r.err = ErrInvalidNumPyFormat
+		return
+	}
+
+	var hdrLen int
+
+	r.read(&r.Header.Major)
+	r.read(&r.Header.Minor)
+	switch r.Header.Major {
+	case 1:
+		var v uint16
+		r.read(&v)
+		hdrLen = int(v)
+	case 2:
+		var v uint32
+		r.read(&v)
+		hdrLen = int(v)
+	default:
+		r.err = fmt.Errorf("npy: invalid major version number (%d)", r.Header.Major)
+	}
+
+	if r.err != nil {
+		return
+	}
+
+	hdr := make([]byte, hdrLen)
+	r.read(&hdr)
+	idx := bytes.LastIndexByte(hdr, '\n')
+	hdr = hdr[:idx]
+	r.readDescr(hdr)
+}
+
+func (r *Reader) readDescr(buf []byte) {
+	if r.err != nil {
+		return
+	}
+
+	var (
+		descrKey = []byte("'descr': ")
+		orderKey = []byte("'fortran_order': ")
+		shapeKey = []byte("'shape': ")
+		trailer  = []byte(", ")
+	)
+
+	begDescr := bytes.Index(buf, descrKey)
+	begOrder := bytes.Index(buf, orderKey)
+	begShape := bytes.Index(buf, shapeKey)
+	endDescr := bytes.Index(buf, []byte("}"))
+	if begDescr < 0 || begOrder < 0 || begShape < 0 {
+		r.err = fmt.Errorf("npy: invalid dictionary format")
+		return
+	}
+
+	descr := string(buf[begDescr+len(descrKey)+1 : begOrder-len(trailer)-1])
+	order := string(buf[begOrder+len(orderKey) : begShape-len(trailer)])
+	shape := buf[begShape+len(shapeKey) : endDescr-len(trailer)]
+
+	r.Header.Descr.Type = descr // FIXME(sbinet): better handling
+	switch order {
+	case "False":
+		r.Header.Descr.Fortran = false
+	case "True":
+		r.Header.Descr.Fortran = true
+	default:
+		r.err = fmt.Errorf("npy: invalid 'fortran_order' value (%v)", order)
+		return
+	}
+
+	if string(shape) == "()" {
+		r.Header.Descr.Shape = nil
+		return
+	}
+
+	shape = shape[1 : len(shape)-1]
+	toks := strings.Split(string(shape), ",")
+	for _, tok := range toks {
+		tok = strings.TrimSpace(tok)
+		if tok == "" {
+			continue
+		}
+		i, err := strconv.Atoi(tok)
+		if err != nil {
+			r.err = err
+			return
+		}
+		r.Header.Descr.Shape = append(r.Header.Descr.Shape, int(i))
+	}
+
+}
+
+// Read reads the numpy-array data from the underlying NumPy file.
+// Read returns an error if the on-disk data type and the provided one
+// don't match.
+//
+// See npy.Read() for documentation.
+func (r *Reader) Read(ptr interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	rv := reflect.ValueOf(ptr)
+	if !rv.IsValid() || rv.Kind() != reflect.Ptr {
+		return errNotPtr
+	}
+
+	if rv.IsNil() {
+		return errNilPtr
+	}
+
+	nelems := numElems(r.Header.Descr.Shape)
+	dt, err := newDtype(r.Header.Descr.Type)
+	if err != nil {
+		return err
+	}
+	r.order = dt.order
+
+	switch vptr := ptr.(type) {
+	case *int:
+		v, err := r.readInt(dt)
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = v
+		return r.err
+
+	case *[]int:
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]int, n)
+		}
+		for i := 0; i < n; i++ {
+			v, err := r.readInt(dt)
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = v
+		}
+		return r.err
+
+	case *uint:
+		v, err := r.readUint(dt)
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = v
+		return r.err
+
+	case *[]uint:
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]uint, n)
+		}
+		for i := 0; i < n; i++ {
+			v, err := r.readUint(dt)
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = v
+		}
+		return r.err
+
+	case *mat.Dense:
+		var data []float64
+		err := r.Read(&data)
+		if err != nil && err != io.EOF {
+			r.err = err
+			return r.err
+		}
+		nrows, ncols, err := dimsFromShape(r.Header.Descr.Shape)
+		if err != nil && err != io.EOF {
+			r.err = err
+			return r.err
+		}
+		if r.Header.Descr.Fortran {
+			*vptr = *mat.NewDense(nrows, ncols, nil)
+			i := 0
+			for icol := 0; icol < ncols; icol++ {
+				for irow := 0; irow < nrows; irow++ {
+					vptr.Set(irow, icol, data[i])
+					i++
+				}
+			}
+		} else {
+			*vptr = *mat.NewDense(nrows, ncols, data)
+		}
+		return r.err
+
+	case *bool:
+		if dt.rt != boolType {
+			return ErrTypeMismatch
+		}
+		var buf [1]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		switch buf[0] {
+		case 0:
+			*vptr = false
+		case 1:
+			*vptr = true
+		}
+		return r.err
+
+	case *[]bool:
+		if dt.rt != boolType {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]bool, n)
+		}
+		var buf [1]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			switch buf[0] {
+			case 0:
+				(*vptr)[i] = false
+			case 1:
+				(*vptr)[i] = true
+			}
+		}
+		return r.err
+
+	case *int8:
+		if dt.rt != int8Type {
+			return ErrTypeMismatch
+		}
+		var buf [1]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = int8(buf[0])
+		return r.err
+
+	case *[]int8:
+		if dt.rt != int8Type {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]int8, n)
+		}
+		var buf [1]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = int8(buf[0])
+		}
+		return r.err
+
+	case *int16:
+		if dt.rt != int16Type {
+			return ErrTypeMismatch
+		}
+		var buf [2]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = int16(dt.order.Uint16(buf[:]))
+		return r.err
+
+	case *[]int16:
+		if dt.rt != int16Type {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]int16, n)
+		}
+		var buf [2]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = int16(dt.order.Uint16(buf[:]))
+		}
+		return r.err
+
+	case *int32:
+		if dt.rt != int32Type {
+			return ErrTypeMismatch
+		}
+		var buf [4]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = int32(dt.order.Uint32(buf[:]))
+		return r.err
+
+	case *[]int32:
+		if dt.rt != int32Type {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]int32, n)
+		}
+		var buf [4]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = int32(dt.order.Uint32(buf[:]))
+		}
+		return r.err
+
+	case *int64:
+		if dt.rt != int64Type {
+			return ErrTypeMismatch
+		}
+		var buf [8]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = int64(dt.order.Uint64(buf[:]))
+		return r.err
+
+	case *[]int64:
+		if dt.rt != int64Type {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]int64, n)
+		}
+		var buf [8]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = int64(dt.order.Uint64(buf[:]))
+		}
+		return r.err
+
+	case *uint8:
+		if dt.rt != uint8Type {
+			return ErrTypeMismatch
+		}
+		var buf [1]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = buf[0]
+		return r.err
+
+	case *[]uint8:
+		if dt.rt != uint8Type {
+			return ErrTypeMismatch
+		}
+		var buf [1]byte
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]uint8, n)
+		}
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = buf[0]
+		}
+		return r.err
+
+	case *uint16:
+		if dt.rt != uint16Type {
+			return ErrTypeMismatch
+		}
+		var buf [2]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = dt.order.Uint16(buf[:])
+		return r.err
+
+	case *[]uint16:
+		if dt.rt != uint16Type {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]uint16, n)
+		}
+		var buf [2]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = dt.order.Uint16(buf[:])
+		}
+		return r.err
+
+	case *uint32:
+		if dt.rt != uint32Type {
+			return ErrTypeMismatch
+		}
+		var buf [4]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = dt.order.Uint32(buf[:])
+		return r.err
+
+	case *[]uint32:
+		if dt.rt != uint32Type {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]uint32, n)
+		}
+		var buf [4]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = dt.order.Uint32(buf[:])
+		}
+		return r.err
+
+	case *uint64:
+		if dt.rt != uint64Type {
+			return ErrTypeMismatch
+		}
+		var buf [8]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = dt.order.Uint64(buf[:])
+		return r.err
+
+	case *[]uint64:
+		if dt.rt != uint64Type {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]uint64, n)
+		}
+		var buf [8]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = dt.order.Uint64(buf[:])
+		}
+		return r.err
+
+	case *float32:
+		if dt.rt != float32Type {
+			return ErrTypeMismatch
+		}
+		var buf [4]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = math.Float32frombits(dt.order.Uint32(buf[:]))
+		return r.err
+
+	case *[]float32:
+		if dt.rt != float32Type {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]float32, n)
+		}
+		var buf [4]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = math.Float32frombits(dt.order.Uint32(buf[:]))
+		}
+		return r.err
+
+	case *float64:
+		if dt.rt != float64Type {
+			return ErrTypeMismatch
+		}
+		var buf [8]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		*vptr = math.Float64frombits(dt.order.Uint64(buf[:]))
+		return r.err
+
+	case *[]float64:
+		if dt.rt != float64Type {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]float64, n)
+		}
+		var buf [8]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			(*vptr)[i] = math.Float64frombits(dt.order.Uint64(buf[:]))
+		}
+		return r.err
+
+	case *complex64:
+		if dt.rt != complex64Type {
+			return ErrTypeMismatch
+		}
+		var buf [8]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		rcplx := math.Float32frombits(dt.order.Uint32(buf[0:4]))
+		icplx := math.Float32frombits(dt.order.Uint32(buf[4:8]))
+		*vptr = complex(rcplx, icplx)
+		return r.err
+
+	case *[]complex64:
+		if dt.rt != complex64Type {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]complex64, n)
+		}
+		var buf [8]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			rcplx := math.Float32frombits(dt.order.Uint32(buf[0:4]))
+			icplx := math.Float32frombits(dt.order.Uint32(buf[4:8]))
+			(*vptr)[i] = complex(rcplx, icplx)
+		}
+		return r.err
+
+	case *complex128:
+		if dt.rt != complex128Type {
+			return ErrTypeMismatch
+		}
+		var buf [16]byte
+		_, err := io.ReadFull(r.r, buf[:])
+		if err != nil {
+			return r.elemErr(0, err)
+		}
+		rcplx := math.Float64frombits(dt.order.Uint64(buf[0:8]))
+		icplx := math.Float64frombits(dt.order.Uint64(buf[8:16]))
+		*vptr = complex(rcplx, icplx)
+		return r.err
+
+	case *[]complex128:
+		if dt.rt != complex128Type {
+			return ErrTypeMismatch
+		}
+		n := min(len(*vptr), nelems)
+		if n == 0 {
+			n = nelems
+			*vptr = make([]complex128, n)
+		}
+		var buf [16]byte
+		for i := 0; i < n; i++ {
+			_, err := io.ReadFull(r.r, buf[:])
+			if err != nil {
+				return r.elemErr(i, err)
+			}
+			rcplx := math.Float64frombits(dt.order.Uint64(buf[0:8]))
+			icplx := math.Float64frombits(dt.order.Uint64(buf[8:16]))
+			(*vptr)[i] = complex(rcplx, icplx)
+		}
+		return r.err
+
+	case *string:
+		if dt.rt != stringType {
+			return ErrTypeMismatch
+		}
+
+		switch {
+		case dt.utf:
+			raw, err := ioutil.ReadAll(io.LimitReader(r.r, utf8.UTFMax*int64(dt.size)))
+			if err != nil {
+				r.err = err
+				return r.err
+			}
+			var str string
+			for len(raw) > 0 {
+				r, size := utf8.DecodeRune(raw)
+				str += string(r)
+				raw = raw[size:]
+			}
+			*vptr = str
+			return r.err
+
+		case !dt.utf:
+			buf, err := ioutil.ReadAll(io.LimitReader(r.r, int64(dt.size)))
+			if err != nil {
+				r.err = err
+				return r.err
+			}
+			n := bytes.Index(buf, []byte{0})
+			if n > 0 {
+				buf = buf[:n]
+			}
+			*vptr = string(buf)
+			return r.err
+		}
+	}
+
+	rv = reflect.Indirect(rv)
+	switch rv.Kind() {
+	case reflect.Slice:
+		rv.SetLen(0)
+		elt := rv.Type().Elem()
+		v := reflect.New(dt.rt).Elem()
+		slice := rv
+		for i := 0; i < nelems; i++ {
+			err := r.Read(v.Addr().Interface())
+			if err != nil && err != io.EOF {
+				return r.elemErr(i, err)
+			}
+			slice = reflect.Append(slice, v.Convert(elt))
+		}
+		rv.Set(slice)
+		return r.err
+
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Array {
+			return r.readNested(rv, dt)
+		}
+		if nelems > rv.Type().Len() {
+			return errDims
+		}
+
+		elt := rv.Type().Elem()
+		v := reflect.New(dt.rt).Elem()
+		for i := 0; i < nelems; i++ {
+			err := r.Read(v.Addr().Interface())
+			if err != nil && err != io.EOF {
+				return r.elemErr(i, err)
+			}
+			rv.Index(i).Set(v.Convert(elt))
+		}
+		return r.err
+
+	case reflect.Bool:
+		if !dt.rt.ConvertibleTo(rv.Type()) {
+			return errNoConv
+		}
+		var v uint8
+		r.read(&v)
+		rv.SetBool(v == 1)
+		return r.err
+
+	case reflect.Int, reflect.Uint,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.Complex64, reflect.Complex128:
+		v := reflect.New(dt.rt).Elem()
+		if !dt.rt.ConvertibleTo(rv.Type()) {
+			return errNoConv
+		}
+		r.read(v.Addr().Interface())
+		rv.Set(v.Convert(rv.Type()))
+		return r.err
+
+	case reflect.String, reflect.Map, reflect.Chan, reflect.Interface, reflect.Struct:
+		return fmt.Errorf("npy: type %v not supported", rv.Addr().Type())
+	}
+
+	panic("unreachable")
+}
+
+const (
+	maxInt  = int64(^uint(0) >> 1)
+	minInt  = -maxInt - 1
+	maxUint = uint64(^uint(0))
+)
+
+// readInt reads a single signed integer element of any on-disk width
+// and converts it to a platform-sized int.
+// readInt returns an error if the element is truncated or if the value
+// does not fit in an int.
+func (r *Reader) readInt(dt dType) (int, error) {
+	switch dt.rt {
+	case int8Type, int16Type, int32Type, int64Type:
+	default:
+		return 0, ErrTypeMismatch
+	}
+	var buf [8]byte
+	b := buf[:dt.rt.Size()]
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return 0, err
+	}
+
+	var v int64
+	switch dt.rt {
+	case int8Type:
+		v = int64(int8(b[0]))
+	case int16Type:
+		v = int64(int16(r.order.Uint16(b)))
+	case int32Type:
+		v = int64(int32(r.order.Uint32(b)))
+	case int64Type:
+		v = int64(r.order.Uint64(b))
+	}
+	if v < minInt || v > maxInt {
+		return 0, fmt.Errorf("npy: value %d overflows int", v)
+	}
+	return int(v), nil
+}
+
+// readUint reads a single unsigned integer element of any on-disk width
+// and converts it to a platform-sized uint.
+// readUint returns an error if the element is truncated or if the value
+// does not fit in a uint.
+func (r *Reader) readUint(dt dType) (uint, error) {
+	switch dt.rt {
+	case uint8Type, uint16Type, uint32Type, uint64Type:
+	default:
+		return 0, ErrTypeMismatch
+	}
+	var buf [8]byte
+	b := buf[:dt.rt.Size()]
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return 0, err
+	}
+
+	var v uint64
+	switch dt.rt {
+	case uint8Type:
+		v = uint64(b[0])
+	case uint16Type:
+		v = uint64(r.order.Uint16(b))
+	case uint32Type:
+		v = uint64(r.order.Uint32(b))
+	case uint64Type:
+		v = r.order.Uint64(b)
+	}
+	if v > maxUint {
+		return 0, fmt.Errorf("npy: value %d overflows uint", v)
+	}
+	return uint(v), nil
+}
+
+// readNested reads a multi-dimensional numpy-array into the nested Go
+// array rv, whose dimensions must match the on-disk shape.
+func (r *Reader) readNested(rv reflect.Value, dt dType) error {
+	shape := r.Header.Descr.Shape
+	if r.Header.Descr.Fortran && len(shape) > 1 {
+		return fmt.Errorf("npy: Fortran-order array with shape %v can only be read into a flat slice", shape)
+	}
+
+	rt := rv.Type()
+	for _, dim := range shape {
+		if rt.Kind() != reflect.Array || rt.Len() != dim {
+			return errDims
+		}
+		rt = rt.Elem()
+	}
+	if rt.Kind() == reflect.Array {
+		return errDims
+	}
+	if !dt.rt.ConvertibleTo(rt) {
+		return errNoConv
+	}
+
+	var (
+		v    = reflect.New(dt.rt).Elem()
+		i    = 0
+		fill func(rv reflect.Value) error
+	)
+	fill = func(rv reflect.Value) error {
+		if rv.Kind() != reflect.Array {
+			err := r.Read(v.Addr().Interface())
+			if err != nil && err != io.EOF {
+				return r.elemErr(i, err)
+			}
+			rv.Set(v.Convert(rv.Type()))
+			i++
+			return nil
+		}
+		for j := 0; j < rv.Len(); j++ {
+			err := fill(rv.Index(j))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err := fill(rv)
+	if err != nil {
+		return err
+	}
+	return r.err
+}
+
+// ReadError is the error returned by Reader when the data of an array
+// element could not be read, e.g. because the underlying io.Reader ended
+// before all the elements described by the header were read.
+type ReadError struct {
+	Index int   // index of the element that could not be read
+	Err   error // underlying error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("npy: could not read element %d: %v", e.Index, e.Err)
+}
+
+func (e *ReadError) Unwrap() error { return e.Err }
+
+// elemErr records err as the error that occurred while reading the i-th
+// element of the array.
+func (r *Reader) elemErr(i int, err error) error {
+	var rerr *ReadError
+	if errors.As(err, &rerr) {
+		err = rerr.Err
+	}
+	r.err = &ReadError{Index: i, Err: err}
+	return r.err
+}
+
+func dimsFromShape(shape []int) (int, int, error) {
+	nrows := 0
+	ncols := 0
+
+	switch len(shape) {
+	default:
+		return -1, -1, fmt.Errorf("npy: array shape not supported %v (read into a flat slice instead)", shape)
+
+	case 0:
+		nrows = 1
+		ncols = 1
+
+	case 1:
+		nrows = shape[0]
+		ncols = 1
+
+	case 2:
+		nrows = shape[0]
+		ncols = shape[1]
+	}
+
+	return nrows, ncols, nil
+}
+
+func (r *Reader) read(v interface{}) {
+	if r.err != nil {
+		return
+	}
+	r.err = binary.Read(r.r, r.order, v)
+}
+
+func numElems(shape []int) int {
+	n := 1
+	for _, v := range shape {
+		n *= v
+	}
+	return n
+}
+
+// TypeFrom returns the reflect.Type corresponding to the numpy-dtype string, if any.
+func TypeFrom(dtype string) reflect.Type {
+	dt, err := newDtype(dtype)
+	if err != nil {
+		return nil
+	}
+	return dt.rt
+}
+
+var (
+	reStrPre  = regexp.MustCompile(`^[|]*?(\d.*)[Sa]$`)
+	reStrPost = regexp.MustCompile(`^[|]*?[Sa](\d.*)$`)
+	reUniPre  = regexp.MustCompile(`^[<|>]*?(\d.*)U$`)
+	reUniPost = regexp.MustCompile(`^[<|>]*?U(\d.*)$`)
+)
+
+func stringLen(dtype string) (int, error) {
+	if m := reStrPre.FindStringSubmatch(dtype); m != nil {
+		v, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, err
+		}
+		return int(v), nil
+	}
+	if m := reStrPost.FindStringSubmatch(dtype); m != nil {
+		v, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, err
+		}
+		return int(v), nil
+	}
+	if m := reUniPre.FindStringSubmatch(dtype); m != nil {
+		v, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, err
+		}
+		return int(v), nil
+	}
+	if m := reUniPost.FindStringSubmatch(dtype); m != nil {
+		v, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, err
+		}
+		return int(v), nil
+	}
+	return 0, fmt.Errorf("npy: %q is not a string-like dtype", dtype)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,642 @@
+// Copyright 2016 The npyio Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package npy
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestReaderDense(t *testing.T) {
+	want := map[string]map[bool]*mat.Dense{
+		"2x3": map[bool]*mat.Dense{
+			false: mat.NewDense(2, 3, []float64{0, 1, 2, 3, 4, 5}), // row-major
+			true:  mat.NewDense(2, 3, []float64{0, 2, 4, 1, 3, 5}), // col-major
+		},
+		"6x1": map[bool]*mat.Dense{
+			false: mat.NewDense(6, 1, []float64{0, 1, 2, 3, 4, 5}),
+			true:  mat.NewDense(6, 1, []float64{0, 1, 2, 3, 4, 5}),
+		},
+		"1x1": map[bool]*mat.Dense{
+			false: mat.NewDense(1, 1, []float64{42}),
+			true:  mat.NewDense(1, 1, []float64{42}),
+		},
+		"scalar": map[bool]*mat.Dense{
+			false: mat.NewDense(1, 1, []float64{42}),
+			true:  mat.NewDense(1, 1, []float64{42}),
+		},
+	}
+
+	for _, dt := range []string{
+		"float64",
+	} {
+		for _, order := range []string{"f", "c"} {
+			for _, shape := range []string{"2x3", "6x1", "1x1", "scalar"} {
+
+				fname := fmt.Sprintf("../testdata/data_%s_%s_%sorder.npy", dt, shape, order)
+				f, err := os.Open(fname)
+				if err != nil {
+					t.Errorf("%v: error: %v\n", fname, err)
+				}
+				defer f.Close()
+
+				r, err := NewReader(f)
+				if err != nil {
+					t.Errorf("%v: error: %v\n", fname, err)
+				}
+
+				var m mat.Dense
+				err = r.Read(&m)
+				if err != nil {
+					t.Errorf("%v: error: %v\n", fname, err)
+				}
+
+				order := r.Header.Descr.Fortran
+				if !mat.Equal(&m, want[shape][order]) {
+					t.Errorf("%v: error.\n got=%v\nwant=%v\n",
+						fname,
+						&m,
+						want[shape][order],
+					)
+				}
+			}
+		}
+	}
+}
+
+func TestReaderSlice(t *testing.T) {
+	want := map[string]map[string]interface{}{
+		"float32": {
+			"2x3":    []float32{0, 1, 2, 3, 4, 5},
+			"6x1":    []float32{0, 1, 2, 3, 4, 5},
+			"1x1":    []float32{42},
+			"scalar": []float32{42},
+		},
+		"float64": {
+			"2x3":    []float64{0, 1, 2, 3, 4, 5},
+			"6x1":    []float64{0, 1, 2, 3, 4, 5},
+			"1x1":    []float64{42},
+			"scalar": []float64{42},
+		},
+		"int8": {
+			"2x3":    []int8{0, 1, 2, 3, 4, 5},
+			"6x1":    []int8{0, 1, 2, 3, 4, 5},
+			"1x1":    []int8{42},
+			"scalar": []int8{42},
+		},
+		"int16": {
+			"2x3":    []int16{0, 1, 2, 3, 4, 5},
+			"6x1":    []int16{0, 1, 2, 3, 4, 5},
+			"1x1":    []int16{42},
+			"scalar": []int16{42},
+		},
+		"int32": {
+			"2x3":    []int32{0, 1, 2, 3, 4, 5},
+			"6x1":    []int32{0, 1, 2, 3, 4, 5},
+			"1x1":    []int32{42},
+			"scalar": []int32{42},
+		},
+		"int64": {
+			"2x3":    []int64{0, 1, 2, 3, 4, 5},
+			"6x1":    []int64{0, 1, 2, 3, 4, 5},
+			"1x1":    []int64{42},
+			"scalar": []int64{42},
+		},
+		"uint8": {
+			"2x3":    []uint8{0, 1, 2, 3, 4, 5},
+			"6x1":    []uint8{0, 1, 2, 3, 4, 5},
+			"1x1":    []uint8{42},
+			"scalar": []uint8{42},
+		},
+		"uint16": {
+			"2x3":    []uint16{0, 1, 2, 3, 4, 5},
+			"6x1":    []uint16{0, 1, 2, 3, 4, 5},
+			"1x1":    []uint16{42},
+			"scalar": []uint16{42},
+		},
+		"uint32": {
+			"2x3":    []uint32{0, 1, 2, 3, 4, 5},
+			"6x1":    []uint32{0, 1, 2, 3, 4, 5},
+			"1x1":    []uint32{42},
+			"scalar": []uint32{42},
+		},
+		"uint64": {
+			"2x3":    []uint64{0, 1, 2, 3, 4, 5},
+			"6x1":    []uint64{0, 1, 2, 3, 4, 5},
+			"1x1":    []uint64{42},
+			"scalar": []uint64{42},
+		},
+	}
+
+	for _, dt := range []string{
+		"float32", "float64",
+		"int8", "int16", "int32", "int64",
+		"uint8", "uint16", "uint32", "uint64",
+	} {
+		for _, order := range []string{"f", "c"} {
+			for _, shape := range []string{"2x3", "6x1", "1x1", "scalar"} {
+
+				fname := fmt.Sprintf("../testdata/data_%s_%s_%sorder.npy", dt, shape, order)
+				f, err := os.Open(fname)
+				if err != nil {
+					t.Errorf("%v: error: %v\n", fname, err)
+				}
+				defer f.Close()
+
+				r, err := NewReader(f)
+				if err != nil {
+					t.Errorf("%v: error: %v\n", fname, err)
+				}
+
+				rt := TypeFrom(dt)
+				if rt == nil {
+					t.Errorf("%v: no reflect type for %v\n", fname, dt)
+					continue
+				}
+				data := reflect.New(reflect.SliceOf(rt))
+				err = r.Read(data.Interface())
+				if err != nil {
+					t.Errorf("%v: error: %v\n", fname, err)
+				}
+				if !reflect.DeepEqual(data.Elem().Interface(), want[dt][shape]) {
+					t.Errorf("%v: error.\n got=%v\nwant=%v\n",
+						fname,
+						data.Elem().Interface(),
+						want[dt][shape],
+					)
+				}
+				if wslice := want[dt][shape]; reflect.ValueOf(wslice).Len() > 1 {
+					if _, err := f.Seek(0, 0); err != nil {
+						t.Errorf("%v: error: %v\n", fname, err)
+					}
+					r.readHeader()
+					const psize = 3
+					pslice := reflect.MakeSlice(reflect.SliceOf(rt), psize, psize)
+					data = reflect.New(pslice.Type())
+					data.Elem().Set(pslice)
+					err = r.Read(data.Interface())
+					if err != nil {
+						t.Errorf("%v: error: %v\n", fname, err)
+					}
+					if pwant := reflect.ValueOf(wslice).Slice(0, 3); !reflect.DeepEqual(pslice.Interface(), pwant.Interface()) {
+						t.Errorf("%v: error.\n got=%v\nwant=%v\n",
+							fname,
+							pslice,
+							pwant,
+						)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestReaderNDimSlice(t *testing.T) {
+	want := make([]float64, 2*3*4)
+	for i := range want {
+		want[i] = float64(i)
+	}
+
+	f, err := os.Open("../testdata/data_float64_2x3x4_corder.npy")
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+	defer f.Close()
+
+	var data []float64
+	err = Read(f, &data)
+	if err != nil {
+		t.Errorf("error reading data: %v\n", err)
+	}
+
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("error.\n got=%v\nwant=%v\n", data, want)
+	}
+}
+
+func TestReaderNaNsInf(t *testing.T) {
+	want := mat.NewDense(4, 1, []float64{math.NaN(), math.Inf(-1), 0, math.Inf(+1)})
+	f, err := os.Open("../testdata/nans_inf.npy")
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+	defer f.Close()
+
+	var m mat.Dense
+	err = Read(f, &m)
+	if err != nil {
+		t.Errorf("error reading data: %v\n", err)
+	}
+
+	for i, v := range []bool{
+		math.IsNaN(m.At(0, 0)),
+		math.IsInf(m.At(1, 0), -1),
+		m.At(2, 0) == 0,
+		math.IsInf(m.At(3, 0), +1),
+	} {
+		if !v {
+			t.Errorf("read test m.At(%d,0) failed\n got=%#v\nwant=%#v\n", i, m.At(i, 0), want.At(i, 0))
+		}
+	}
+}
+
+func TestReaderNpz(t *testing.T) {
+	want := map[string]map[bool]*mat.Dense{
+		"arr0.npy": map[bool]*mat.Dense{
+			false: mat.NewDense(2, 3, []float64{0, 1, 2, 3, 4, 5}), // row-major
+			true:  mat.NewDense(2, 3, []float64{0, 2, 4, 1, 3, 5}), // col-major
+		},
+		"arr1.npy": map[bool]*mat.Dense{
+			false: mat.NewDense(6, 1, []float64{0, 1, 2, 3, 4, 5}),
+			true:  mat.NewDense(6, 1, []float64{0, 1, 2, 3, 4, 5}),
+		},
+	}
+
+	for _, order := range []string{"c", "f"} {
+		fname := fmt.Sprintf("../testdata/data_float64_%sorder.npz", order)
+
+		zr, err := zip.OpenReader(fname)
+		if err != nil {
+			t.Errorf("%s: error: %v\n", fname, err)
+			continue
+		}
+		defer zr.Close()
+
+		for _, zip := range zr.File {
+			f, err := zip.Open()
+			if err != nil {
+				t.Errorf("%s: error opening %s entry: %v\n", fname, zip.Name, err)
+				continue
+			}
+			defer f.Close()
+
+			r, err := NewReader(f)
+			if err != nil {
+				t.Errorf("%s: error creating %s reader: %v\n", fname, zip.Name, err)
+				continue
+			}
+
+			var m mat.Dense
+			err = r.Read(&m)
+			if err != nil {
+				t.Errorf("%s: error reading %s data: %v\n", fname, zip.Name, err)
+				continue
+			}
+
+			corder := r.Header.Descr.Fortran
+			if !mat.Equal(&m, want[zip.Name][corder]) {
+				t.Errorf("%s: error comparing %s.\n got=%v\nwant=%v\n",
+					fname,
+					zip.Name,
+					&m,
+					want[zip.Name][corder],
+				)
+				continue
+			}
+		}
+	}
+}
+
+func TestStringLenDtype(t *testing.T) {
+	for _, test := range []struct {
+		dtype string
+		want  int
+		err   bool
+	}{
+		{
+			dtype: "S66",
+			want:  66,
+		},
+		{
+			dtype: "S6",
+			want:  6,
+		},
+		{
+			dtype: "6S",
+			want:  6,
+		},
+		{
+			dtype: "66S",
+			want:  66,
+		},
+		{
+			dtype: "|S6",
+			want:  6,
+		},
+		{
+			dtype: "|S66",
+			want:  66,
+		},
+		{
+			dtype: "|6S",
+			want:  6,
+		},
+		{
+			dtype: "|66S",
+			want:  66,
+		},
+		{
+			dtype: "a6",
+			want:  6,
+		},
+		{
+			dtype: "6a",
+			want:  6,
+		},
+		{
+			dtype: "|a6",
+			want:  6,
+		},
+		{
+			dtype: "|6a",
+			want:  6,
+		},
+		{
+			dtype: "<U25",
+			want:  25,
+		},
+		{
+			dtype: "|U25",
+			want:  25,
+		},
+		{
+			dtype: ">U25",
+			want:  25,
+		},
+		{
+			dtype: "<25U",
+			want:  25,
+		},
+		{
+			dtype: "|25U",
+			want:  25,
+		},
+		{
+			dtype: ">25U",
+			want:  25,
+		},
+		{
+			dtype: "6S6",
+			err:   true,
+		},
+		{
+			dtype: "6a6",
+			err:   true,
+		},
+		{
+			dtype: "6U6",
+			err:   true,
+		},
+		{
+			dtype: "<i4",
+			err:   true,
+		},
+	} {
+		n, err := stringLen(test.dtype)
+		if err == nil && test.err {
+			t.Errorf("%s: expected an error", test.dtype)
+			continue
+		}
+		if err != nil && !test.err {
+			t.Errorf("%s: error=%v", test.dtype, err)
+			continue
+		}
+		if n != test.want {
+			t.Errorf("%s: got=%d. want=%d", test.dtype, n, test.want)
+			continue
+		}
+	}
+}
+
+func TestReaderTruncated(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data interface{}
+		ptr  func() interface{}
+		idx  int
+	}{
+		{"int8-int", []int8{1, 2, 3}, func() interface{} { return new([]int) }, 2},
+		{"int16-int", []int16{1, 2, 3}, func() interface{} { return new([]int) }, 2},
+		{"int32-int", []int32{1, 2, 3}, func() interface{} { return new([]int) }, 2},
+		{"int64-int", []int64{1, 2, 3}, func() interface{} { return new([]int) }, 2},
+		{"uint8-uint", []uint8{1, 2, 3}, func() interface{} { return new([]uint) }, 2},
+		{"uint16-uint", []uint16{1, 2, 3}, func() interface{} { return new([]uint) }, 2},
+		{"uint32-uint", []uint32{1, 2, 3}, func() interface{} { return new([]uint) }, 2},
+		{"uint64-uint", []uint64{1, 2, 3}, func() interface{} { return new([]uint) }, 2},
+		{"int64-scalar", int64(1), func() interface{} { return new(int) }, 0},
+		{"float32", []float32{1, 2, 3}, func() interface{} { return new([]float32) }, 2},
+		{"float64", []float64{1, 2, 3}, func() interface{} { return new([]float64) }, 2},
+		{"float64-scalar", 1.0, func() interface{} { return new(float64) }, 0},
+		{"bool", []bool{true, false}, func() interface{} { return new([]bool) }, 1},
+		{"dense", []float64{1, 2, 3, 4}, func() interface{} { return new(mat.Dense) }, 3},
+		{"uint64-scalar", uint64(1), func() interface{} { return new(uint) }, 0},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := Write(buf, test.data)
+			if err != nil {
+				t.Fatalf("error writing data: %v", err)
+			}
+			// drop the last byte so the last element is cut short.
+			raw := buf.Bytes()[:buf.Len()-1]
+
+			r, err := NewReader(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("error creating reader: %v", err)
+			}
+			err = r.Read(test.ptr())
+			var rerr *ReadError
+			if !errors.As(err, &rerr) {
+				t.Fatalf("got error %v, want a *ReadError", err)
+			}
+			if rerr.Index != test.idx {
+				t.Fatalf("got index %d, want %d", rerr.Index, test.idx)
+			}
+			if rerr.Err != io.ErrUnexpectedEOF && rerr.Err != io.EOF {
+				t.Fatalf("got underlying error %v, want an EOF", rerr.Err)
+			}
+		})
+	}
+}
+
+func TestReaderInts(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data interface{}
+		want []int
+	}{
+		{"int8", []int8{-128, -1, 0, 1, 127}, []int{-128, -1, 0, 1, 127}},
+		{"int16", []int16{-32768, -1, 0, 32767}, []int{-32768, -1, 0, 32767}},
+		{"int32", []int32{math.MinInt32, 0, math.MaxInt32}, []int{math.MinInt32, 0, math.MaxInt32}},
+		{"int64", []int64{-1 << 40, 0, 1 << 40}, []int{-1 << 40, 0, 1 << 40}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := Write(buf, test.data)
+			if err != nil {
+				t.Fatalf("error writing data: %v", err)
+			}
+			var got []int
+			err = Read(buf, &got)
+			if err != nil {
+				t.Fatalf("error reading data: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReaderUints(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data interface{}
+		want []uint
+	}{
+		{"uint8", []uint8{0, 1, 255}, []uint{0, 1, 255}},
+		{"uint16", []uint16{0, 65535}, []uint{0, 65535}},
+		{"uint32", []uint32{0, math.MaxUint32}, []uint{0, math.MaxUint32}},
+		{"uint64", []uint64{0, 1 << 40}, []uint{0, 1 << 40}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := Write(buf, test.data)
+			if err != nil {
+				t.Fatalf("error writing data: %v", err)
+			}
+			var got []uint
+			err = Read(buf, &got)
+			if err != nil {
+				t.Fatalf("error reading data: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReaderIntsMismatch(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data interface{}
+		ptr  interface{}
+	}{
+		{"float64-int", []float64{1}, new([]int)},
+		{"uint8-int", []uint8{1}, new([]int)},
+		{"int8-uint", []int8{1}, new([]uint)},
+		{"float32-uint", float32(1), new(uint)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := Write(buf, test.data)
+			if err != nil {
+				t.Fatalf("error writing data: %v", err)
+			}
+			err = Read(buf, test.ptr)
+			if !errors.Is(err, ErrTypeMismatch) {
+				t.Fatalf("got error %v, want %v", err, ErrTypeMismatch)
+			}
+		})
+	}
+}
+
+func TestReaderNested(t *testing.T) {
+	var want [2][3][4]float64
+	for i := range want {
+		for j := range want[i] {
+			for k := range want[i][j] {
+				want[i][j][k] = float64(i*12 + j*4 + k)
+			}
+		}
+	}
+
+	for _, test := range []struct {
+		name string
+		file string
+		ptr  interface{}
+		err  error
+		fail bool // any error will do
+	}{
+		{"2x3x4", "../testdata/data_float64_2x3x4_corder.npy", new([2][3][4]float64), nil, false},
+		{"2x3x4-float32", "../testdata/data_float64_2x3x4_corder.npy", new([2][3][4]float32), nil, false},
+		{"2x3x5", "../testdata/data_float64_2x3x4_corder.npy", new([2][3][5]float64), errDims, false},
+		{"2x12", "../testdata/data_float64_2x3x4_corder.npy", new([2][12]float64), errDims, false},
+		{"2x3x4x1", "../testdata/data_float64_2x3x4_corder.npy", new([2][3][4][1]float64), errDims, false},
+		{"2x3x4-bool", "../testdata/data_float64_2x3x4_corder.npy", new([2][3][4]bool), errNoConv, false},
+		{"2x3-forder", "../testdata/data_float64_2x3_forder.npy", new([2][3]float64), nil, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := os.Open(test.file)
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+			defer f.Close()
+
+			err = Read(f, test.ptr)
+			switch {
+			case test.fail:
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			case test.err != nil:
+				if err != test.err {
+					t.Fatalf("got error %v, want %v", err, test.err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("error reading data: %v", err)
+			}
+
+			got := reflect.ValueOf(test.ptr).Elem()
+			for i := range want {
+				for j := range want[i] {
+					for k := range want[i][j] {
+						v := got.Index(i).Index(j).Index(k).Convert(reflect.TypeOf(0.0)).Float()
+						if v != want[i][j][k] {
+							t.Fatalf("element [%d][%d][%d]: got %v, want %v", i, j, k, v, want[i][j][k])
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReaderFortranFlat(t *testing.T) {
+	f, err := os.Open("../testdata/data_float64_2x3_forder.npy")
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	defer f.Close()
+
+	r, err := NewReader(f)
+	if err != nil {
+		t.Fatalf("error creating reader: %v", err)
+	}
+	var data []float64
+	err = r.Read(&data)
+	if err != nil {
+		t.Fatalf("error reading data: %v", err)
+	}
+	// the matrix [[0 2 4] [1 3 5]] in column-major order.
+	if want := []float64{0, 1, 2, 3, 4, 5}; !reflect.DeepEqual(data, want) {
+		t.Fatalf("got %v, want %v", data, want)
+	}
+	if want := []int{2, 3}; !r.Header.Descr.Fortran || !reflect.DeepEqual(r.Header.Descr.Shape, want) {
+		t.Fatalf("got fortran=%v shape=%v, want fortran=true shape=%v", r.Header.Descr.Fortran, r.Header.Descr.Shape, want)
+	}
+}
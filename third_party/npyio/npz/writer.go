@@ -0,0 +1,173 @@
+// Copyright 2020 The npyio Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package npz
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/sbinet/npyio/npy"
+)
+
+// Write writes the values vs to the named npz archive file.
+//
+// The data-array will always be written out in C-order (row-major).
+func Write(name string, vs map[string]interface{}) error {
+	w, err := Create(name)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	err = w.WriteAll(vs)
+	if err != nil {
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Compression methods supported by Writer.SetCompression.
+const (
+	Store   = zip.Store   // no compression
+	Deflate = zip.Deflate // DEFLATE-compressed (the default)
+)
+
+// Writer writes data to a compressed NumPy data file.
+type Writer struct {
+	w  io.Writer
+	wz *zip.Writer
+	wc io.Closer
+
+	method uint16 // compression method of new entries
+}
+
+// Create creates the named compressed NumPy data file for writing.
+func Create(name string) (*Writer, error) {
+	w, err := os.Create(name)
+	if err != nil {
+		return nil, fmt.Errorf("npz: could not create %q: %w", name, err)
+	}
+
+	wz := zip.NewWriter(w)
+
+	return &Writer{
+		w:      w,
+		wz:     wz,
+		wc:     w,
+		method: Deflate,
+	}, nil
+}
+
+// NewWriter returns a new npz writer.
+//
+// The returned npz writer won't close the underlying writer.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w:      w,
+		wz:     zip.NewWriter(w),
+		method: Deflate,
+	}
+}
+
+// SetCompression sets the compression method of the entries written
+// after the call.
+// method is either Store or Deflate. For Deflate, level is one of the
+// compress/flate levels (flate.HuffmanOnly to flate.BestCompression);
+// it is ignored for Store.
+func (w *Writer) SetCompression(method uint16, level int) error {
+	switch method {
+	case Store:
+	case Deflate:
+		if level < flate.HuffmanOnly || level > flate.BestCompression {
+			return fmt.Errorf("npz: invalid compression level %d", level)
+		}
+		w.wz.RegisterCompressor(Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, level)
+		})
+	default:
+		return fmt.Errorf("npz: unsupported compression method %d", method)
+	}
+	w.method = method
+	return nil
+}
+
+// Close closes the npz archive.
+// Close flushes the data to disk.
+func (w *Writer) Close() error {
+	if w.w == nil {
+		return nil
+	}
+
+	var (
+		errz error
+		errc error
+	)
+
+	errz = w.wz.Close()
+	if w.wc != nil {
+		wc := w.wc
+		w.wc = nil
+		errc = wc.Close()
+	}
+
+	w.w = nil
+	w.wz = nil
+
+	if errz != nil {
+		return fmt.Errorf("npz: could not close npz archive: %w", errz)
+	}
+
+	if errc != nil {
+		return fmt.Errorf("npz: could not close npz file: %w", errc)
+	}
+
+	return nil
+}
+
+// Write writes the named NumPy array data to the npz archive.
+func (w *Writer) Write(name string, v interface{}) error {
+	ww, err := w.wz.CreateHeader(&zip.FileHeader{
+		Name:   name,
+		Method: w.method,
+	})
+	if err != nil {
+		return fmt.Errorf("npz: could not create npz entry %q: %w", name, err)
+	}
+
+	err = npy.Write(ww, v)
+	if err != nil {
+		return fmt.Errorf("npz: could not write npz entry %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// WriteAll writes the values vs to the npz archive, in sorted key order.
+func (w *Writer) WriteAll(vs map[string]interface{}) error {
+	ks := make([]string, 0, len(vs))
+	for k := range vs {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+
+	for _, k := range ks {
+		err := w.Write(k, vs[k])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
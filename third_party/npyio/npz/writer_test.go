@@ -0,0 +1,233 @@
+// Copyright 2020 The npyio Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package npz
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestWrite(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want interface{}
+	}{
+		{"dense_2x3", mat.NewDense(2, 3, []float64{0, 1, 2, 3, 4, 5})},
+		{"dense_6x1", mat.NewDense(6, 1, []float64{0, 1, 2, 3, 4, 5})},
+		{"dense_1x6", mat.NewDense(1, 6, []float64{0, 1, 2, 3, 4, 5})},
+		{"dense_1x1", mat.NewDense(1, 1, []float64{42})},
+
+		// scalars
+		{"bool-true", true},
+		{"bool-false", false},
+		{"uint8", uint8(42)},
+		{"uint16", uint16(42)},
+		{"uint32", uint32(42)},
+		{"uint64", uint64(42)},
+		{"int8", int8(42)},
+		{"int16", int16(42)},
+		{"int32", int32(42)},
+		{"int64", int64(42)},
+		{"float32", float32(42)},
+		{"float64", float64(42)},
+		{"cplx64", complex64(42 + 66i)},
+		{"cplx128", complex128(42 + 66i)},
+
+		// arrays
+		{"bool-array", [6]bool{true, true, false, false, true, false}},
+		{"uint8-array", [6]uint8{0, 1, 2, 3, 4, 5}},
+		{"uint16-array", [6]uint16{0, 1, 2, 3, 4, 5}},
+		{"uint32-array", [6]uint32{0, 1, 2, 3, 4, 5}},
+		{"uint64-array", [6]uint64{0, 1, 2, 3, 4, 5}},
+		{"int8-array", [6]int8{0, 1, 2, 3, 4, 5}},
+		{"int16-array", [6]int16{0, 1, 2, 3, 4, 5}},
+		{"int32-array", [6]int32{0, 1, 2, 3, 4, 5}},
+		{"int64-array", [6]int64{0, 1, 2, 3, 4, 5}},
+		{"float32-array", [6]float32{0, 1, 2, 3, 4, 5}},
+		{"float64-array", [6]float64{0, 1, 2, 3, 4, 5}},
+		{"cplx64-array", [6]complex64{0, 1 + 1i, 2 + 2i, 3 + 3i, 4 + 4i, 5 + 5i}},
+		{"cplx128-array", [6]complex128{0, 1 + 1i, 2 + 2i, 3 + 3i, 4 + 4i, 5 + 5i}},
+
+		// slices
+		{"bool-slice", []bool{true, true, false, false, true, false}},
+		{"uint8-slice", []uint8{0, 1, 2, 3, 4, 5}},
+		{"uint16-slice", []uint16{0, 1, 2, 3, 4, 5}},
+		{"uint32-slice", []uint32{0, 1, 2, 3, 4, 5}},
+		{"uint64-slice", []uint64{0, 1, 2, 3, 4, 5}},
+		{"int8-slice", []int8{0, 1, 2, 3, 4, 5}},
+		{"int16-slice", []int16{0, 1, 2, 3, 4, 5}},
+		{"int32-slice", []int32{0, 1, 2, 3, 4, 5}},
+		{"int64-slice", []int64{0, 1, 2, 3, 4, 5}},
+		{"float32-slice", []float32{0, 1, 2, 3, 4, 5}},
+		{"float64-slice", []float64{0, 1, 2, 3, 4, 5}},
+		{"cplx64-slice", []complex64{0, 1 + 1i, 2 + 2i, 3 + 3i, 4 + 4i, 5 + 5i}},
+		{"cplx128-slice", []complex128{0, 1 + 1i, 2 + 2i, 3 + 3i, 4 + 4i, 5 + 5i}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			wz := NewWriter(buf)
+			err := wz.Write(tc.name, tc.want)
+			if err != nil {
+				t.Fatalf("could not write value: %+v", err)
+			}
+
+			err = wz.Close()
+			if err != nil {
+				t.Fatalf("could not close writer: %+v", err)
+			}
+
+			got := reflect.New(reflect.Indirect(reflect.ValueOf(tc.want)).Type())
+			err = Read(bytes.NewReader(buf.Bytes()), tc.name, got.Interface())
+			if err != nil {
+				t.Fatalf("could not read value: %+v", err)
+			}
+
+			got = reflect.Indirect(got)
+			want := reflect.Indirect(reflect.ValueOf(tc.want))
+
+			if got, want := got.Interface(), want.Interface(); !reflect.DeepEqual(got, want) {
+				t.Fatalf(
+					"invalid r/w round-trip:\ngot= %#v\nwant=%#v",
+					got, want,
+				)
+			}
+		})
+	}
+}
+
+func TestWriterSetCompression(t *testing.T) {
+	want := make([]float64, 1000)
+	for i := range want {
+		want[i] = float64(i % 10)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		method uint16
+		level  int
+		err    bool
+	}{
+		{"store", Store, 0, false},
+		{"deflate-default", Deflate, flate.DefaultCompression, false},
+		{"deflate-huffman", Deflate, flate.HuffmanOnly, false},
+		{"deflate-speed", Deflate, flate.BestSpeed, false},
+		{"deflate-best", Deflate, flate.BestCompression, false},
+		{"deflate-invalid-level", Deflate, flate.BestCompression + 1, true},
+		{"invalid-method", 99, 0, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			wz := NewWriter(buf)
+			err := wz.SetCompression(tc.method, tc.level)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("could not set compression: %+v", err)
+			}
+
+			err = wz.Write("data", want)
+			if err != nil {
+				t.Fatalf("could not write data: %+v", err)
+			}
+			err = wz.Close()
+			if err != nil {
+				t.Fatalf("could not close npz: %+v", err)
+			}
+
+			zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("could not open zip: %+v", err)
+			}
+			if got := zr.File[0].Method; got != tc.method {
+				t.Fatalf("got method %d, want %d", got, tc.method)
+			}
+
+			var got []float64
+			err = Read(bytes.NewReader(buf.Bytes()), "data", &got)
+			if err != nil {
+				t.Fatalf("could not read data: %+v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("invalid round-trip")
+			}
+		})
+	}
+}
+
+func TestWriterWriteAll(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		vs   map[string]interface{}
+		keys []string
+		err  bool
+	}{
+		{"empty", map[string]interface{}{}, nil, false},
+		{
+			name: "sorted",
+			vs: map[string]interface{}{
+				"b.npy": []float64{1, 2},
+				"a.npy": int64(3),
+				"c.npy": []uint8{4},
+			},
+			keys: []string{"a.npy", "b.npy", "c.npy"},
+		},
+		{
+			name: "invalid",
+			vs:   map[string]interface{}{"a.npy": struct{}{}},
+			err:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			wz := NewWriter(buf)
+			err := wz.WriteAll(tc.vs)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("could not write values: %+v", err)
+			}
+			err = wz.Close()
+			if err != nil {
+				t.Fatalf("could not close npz: %+v", err)
+			}
+
+			zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("could not open zip: %+v", err)
+			}
+			var keys []string
+			for _, f := range zr.File {
+				keys = append(keys, f.Name)
+			}
+			if !reflect.DeepEqual(keys, tc.keys) {
+				t.Fatalf("got entries %v, want %v", keys, tc.keys)
+			}
+
+			for k, want := range tc.vs {
+				got := reflect.New(reflect.TypeOf(want))
+				err = Read(bytes.NewReader(buf.Bytes()), k, got.Interface())
+				if err != nil {
+					t.Fatalf("could not read %q: %+v", k, err)
+				}
+				if !reflect.DeepEqual(got.Elem().Interface(), want) {
+					t.Fatalf("%q: got %v, want %v", k, got.Elem().Interface(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,833 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/exp/rand"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+type TournamentOptions struct {
+	GameOptions
+
+	// Games is how many games each pair of bots plays, 100,000 if unset
+	Games int
+	// Records if set gets every game written to it as a line of JSON as soon
+	// as it is played, so large tournaments don't need to hold them all
+	Records io.Writer
+	// Seed if set shuffles the order the matchups are played in and gives
+	// bots with their own random source a seed for every game derived from
+	// it and who is playing, so two runs with the same seed play out
+	// identically whatever the Workers. The global random source is left
+	// alone, so bots drawing from it aren't reproducible
+	Seed uint64
+	// Workers is how many matchups are played at the same time, 1 if unset.
+	// A bot is only ever in one matchup at a time so bots don't have to be
+	// safe to share between goroutines
+	Workers int
+	// Timing records how long each matchup and the whole tournament took
+	Timing bool
+	// Deterministic names bots whose moves depend on nothing but the game so
+	// far. When one of them plays itself every game would be the same, so
+	// only one is played and counted Games times. Listing a bot that draws
+	// random numbers or keeps state between games gives wrong results.
+	// GameOptions.EarlyStop only applies to the ones listed that are also
+	// Stationary
+	Deterministic map[string]bool
+	// RandomizeSeating flips a coin for every game to decide which bot sits
+	// as player A, so seat advantages even out within a matchup. Results are
+	// still reported from the matchup's point of view
+	RandomizeSeating bool
+	// Ties is how games that end level count towards wins and losses
+	Ties TiePolicy
+}
+
+// TiePolicy is how a game where both bots scored the same counts
+type TiePolicy int
+
+const (
+	// TieDraw counts a tie as a draw, apart from wins and losses
+	TieDraw TiePolicy = iota
+	// TieHalfWin still counts a tie as a draw, but win and loss rates count
+	// it as half a win and half a loss for each bot, leaving no draws
+	TieHalfWin
+	// TieCooperation gives a tie to whichever bot cooperated more in the
+	// game, it is only a draw if they cooperated as often as each other
+	TieCooperation
+)
+
+// MatchupResult is the tally of every game bot A played against bot B,
+// with A in the seat of player A
+type MatchupResult struct {
+	A      string `json:"a"`
+	B      string `json:"b"`
+	Games  int    `json:"games"`
+	Wins   int    `json:"wins"`   // games A won
+	Losses int    `json:"losses"` // games A lost
+	Draws  int    `json:"draws"`
+	AScore int    `json:"a_score"` // A's total score over every game
+	BScore int    `json:"b_score"` // B's total score over every game
+
+	// Swapped is how many games A actually sat as player B, see
+	// TournamentOptions.RandomizeSeating
+	Swapped int `json:"swapped,omitempty"`
+
+	// MutualCooperations is how many rounds both bots cooperated in over
+	// every game, out of Rounds scored rounds
+	MutualCooperations int `json:"mutual_cooperations"`
+	Rounds             int `json:"rounds"`
+
+	// Cooperations counts for each round how many times either bot
+	// cooperated in it over every game
+	Cooperations []int `json:"cooperations"`
+
+	// ACooperations and BCooperations are how many times each bot
+	// cooperated over every game, out of Moves moves each, warmup included
+	ACooperations int `json:"a_cooperations"`
+	BCooperations int `json:"b_cooperations"`
+	Moves         int `json:"moves"`
+
+	Elapsed time.Duration `json:"elapsed,omitempty"` // time taken to play, only set with Timing
+}
+
+// GamesPerSecond is how quickly the matchup was played, only known with Timing
+func (m MatchupResult) GamesPerSecond() float64 {
+	return gamesPerSecond(m.Games, m.Elapsed)
+}
+
+// AllDraws is true when every game of the matchup was drawn, like two
+// CooperateBots playing each other, so its win rate says nothing
+func (m MatchupResult) AllDraws() bool {
+	return m.Games > 0 && m.Draws == m.Games
+}
+
+// add tallies up one more game of the matchup, with ties settled by the
+// policy
+func (m *MatchupResult) add(game Game, ties TiePolicy) {
+	lead := game.AScore - game.BScore
+	if lead == 0 && ties == TieCooperation {
+		lead = countMoves(game.AHistory, Cooperate) - countMoves(game.BHistory, Cooperate)
+	}
+	if lead == 0 {
+		m.Draws++
+	}
+	if lead > 0 {
+		m.Wins++
+	}
+	if lead < 0 {
+		m.Losses++
+	}
+	m.AScore += game.AScore
+	m.BScore += game.BScore
+	m.MutualCooperations += game.MutualCooperations()
+	m.Rounds += len(game.AHistory) - game.WarmupRounds
+
+	for len(m.Cooperations) < len(game.AHistory) {
+		m.Cooperations = append(m.Cooperations, 0)
+	}
+	for i := range game.AHistory {
+		if game.AHistory[i] == Cooperate {
+			m.Cooperations[i]++
+			m.ACooperations++
+		}
+		if game.BHistory[i] == Cooperate {
+			m.Cooperations[i]++
+			m.BCooperations++
+		}
+	}
+	m.Moves += len(game.AHistory)
+}
+
+// merge adds the tallies of other, more games of the same matchup, to m
+func (m *MatchupResult) merge(other MatchupResult) {
+	m.Games += other.Games
+	m.Wins += other.Wins
+	m.Losses += other.Losses
+	m.Draws += other.Draws
+	m.AScore += other.AScore
+	m.BScore += other.BScore
+	m.Swapped += other.Swapped
+	m.MutualCooperations += other.MutualCooperations
+	m.Rounds += other.Rounds
+	m.ACooperations += other.ACooperations
+	m.BCooperations += other.BCooperations
+	m.Moves += other.Moves
+	m.Elapsed += other.Elapsed
+
+	cooperations := make([]int, len(m.Cooperations))
+	copy(cooperations, m.Cooperations)
+	for len(cooperations) < len(other.Cooperations) {
+		cooperations = append(cooperations, 0)
+	}
+	for i, c := range other.Cooperations {
+		cooperations[i] += c
+	}
+	m.Cooperations = cooperations
+}
+
+// CooperationByRound is the fraction of moves in each round that were
+// cooperations
+func (m MatchupResult) CooperationByRound() []float64 {
+	return cooperationByRound(m.Cooperations, m.Games)
+}
+
+func cooperationByRound(cooperations []int, games int) []float64 {
+	fractions := make([]float64, len(cooperations))
+	if games == 0 {
+		return fractions
+	}
+	for i, c := range cooperations {
+		fractions[i] = float64(c) / float64(2*games)
+	}
+	return fractions
+}
+
+type TournamentResult struct {
+	Matchups []MatchupResult `json:"matchups"`
+	Ties     TiePolicy       `json:"ties,omitempty"`    // how ties were counted
+	Elapsed  time.Duration   `json:"elapsed,omitempty"` // time taken to play, only set with Timing
+}
+
+// MergeTournamentResults combines the results of a tournament played in
+// parts, like shards run on different machines. Matchups only one part
+// played are kept as they are and matchups played in more than one have
+// their games added together, so every rate worked out from the merged
+// result is over all of its games. Elapsed is the total time every part
+// took. The parts have to have counted ties the same way
+func MergeTournamentResults(parts ...TournamentResult) (TournamentResult, error) {
+	var merged TournamentResult
+	type key struct{ a, b string }
+	index := map[key]int{}
+	for i, part := range parts {
+		if i == 0 {
+			merged.Ties = part.Ties
+		} else if part.Ties != merged.Ties {
+			return TournamentResult{}, fmt.Errorf("part %d counted ties with policy %d, part 0 with %d", i, part.Ties, merged.Ties)
+		}
+		merged.Elapsed += part.Elapsed
+
+		for _, m := range part.Matchups {
+			k := key{m.A, m.B}
+			if j, ok := index[k]; ok {
+				merged.Matchups[j].merge(m)
+				continue
+			}
+			index[k] = len(merged.Matchups)
+			m.Cooperations = append([]int(nil), m.Cooperations...)
+			merged.Matchups = append(merged.Matchups, m)
+		}
+	}
+	return merged, nil
+}
+
+// Save writes the result to path as JSON with the matchups sorted, so the
+// same results always make the same file and can be kept as a baseline
+func (r TournamentResult) Save(path string) error {
+	sorted := r
+	sorted.Matchups = append([]MatchupResult(nil), r.Matchups...)
+	sort.Slice(sorted.Matchups, func(i, j int) bool {
+		a, b := sorted.Matchups[i], sorted.Matchups[j]
+		if a.A != b.A {
+			return a.A < b.A
+		}
+		return a.B < b.B
+	})
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sorted); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// LoadTournamentResult reads a result written by Save
+func LoadTournamentResult(path string) (TournamentResult, error) {
+	var r TournamentResult
+	file, err := os.Open(path)
+	if err != nil {
+		return r, err
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&r); err != nil {
+		return r, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Equal is true if both results have the same outcome for every matchup,
+// whatever order they were played in and however long they took
+func (r TournamentResult) Equal(other TournamentResult) bool {
+	if len(r.Matchups) != len(other.Matchups) {
+		return false
+	}
+
+	type key struct{ a, b string }
+	matchups := make(map[key]MatchupResult, len(r.Matchups))
+	for _, m := range r.Matchups {
+		m.Elapsed = 0
+		matchups[key{m.A, m.B}] = m
+	}
+	for _, m := range other.Matchups {
+		m.Elapsed = 0
+		if !reflect.DeepEqual(matchups[key{m.A, m.B}], m) {
+			return false
+		}
+	}
+	return true
+}
+
+// Games is the total number of games played
+func (r TournamentResult) Games() int {
+	games := 0
+	for _, m := range r.Matchups {
+		games += m.Games
+	}
+	return games
+}
+
+// GamesPerSecond is how quickly the tournament was played, only known with
+// Timing
+func (r TournamentResult) GamesPerSecond() float64 {
+	return gamesPerSecond(r.Games(), r.Elapsed)
+}
+
+func gamesPerSecond(games int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(games) / elapsed.Seconds()
+}
+
+// Standing is how a single bot did over all of its matchups
+type Standing struct {
+	Games  int
+	Wins   int
+	Losses int
+	Draws  int
+	Score  int
+	Ties   TiePolicy
+}
+
+// WinRate is the percentage of games won, counting draws as half a win
+// with TieHalfWin
+func (s Standing) WinRate() float64 {
+	if s.Ties == TieHalfWin {
+		return s.rate(s.Wins) + s.rate(s.Draws)/2
+	}
+	return s.rate(s.Wins)
+}
+
+// LossRate is the percentage of games lost, counting draws as half a loss
+// with TieHalfWin
+func (s Standing) LossRate() float64 {
+	if s.Ties == TieHalfWin {
+		return s.rate(s.Losses) + s.rate(s.Draws)/2
+	}
+	return s.rate(s.Losses)
+}
+
+// DrawRate is the percentage of games drawn, always 0 with TieHalfWin
+func (s Standing) DrawRate() float64 {
+	if s.Ties == TieHalfWin {
+		return 0
+	}
+	return s.rate(s.Draws)
+}
+
+func (s Standing) rate(count int) float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return (float64(count) / float64(s.Games)) * 100
+}
+
+// CooperationByRound is the fraction of moves in each round that were
+// cooperations over the whole tournament
+func (r TournamentResult) CooperationByRound() []float64 {
+	var cooperations []int
+	for _, m := range r.Matchups {
+		for len(cooperations) < len(m.Cooperations) {
+			cooperations = append(cooperations, 0)
+		}
+		for i, c := range m.Cooperations {
+			cooperations[i] += c
+		}
+	}
+	return cooperationByRound(cooperations, r.Games())
+}
+
+// MoveEntropy is the Shannon entropy in bits of how often the named bot
+// cooperated and defected over every game it played, from either seat. It
+// is 0 for a bot that always plays the same move and 1 for one that plays
+// each half the time. A high entropy points to a bot moving at random,
+// though one copying a random opponent, like tit for tat, scores high too
+func (r TournamentResult) MoveEntropy(name string) float64 {
+	cooperations, moves := r.moveCounts(name)
+	if moves == 0 {
+		return 0
+	}
+
+	entropy := 0.0
+	for _, count := range []int{cooperations, moves - cooperations} {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / float64(moves)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// CooperationRate is the fraction of its moves the named bot cooperated
+// with over every game it played, from either seat
+func (r TournamentResult) CooperationRate(name string) float64 {
+	cooperations, moves := r.moveCounts(name)
+	if moves == 0 {
+		return 0
+	}
+	return float64(cooperations) / float64(moves)
+}
+
+// moveCounts is how many times the named bot cooperated and how many moves
+// it made in total
+func (r TournamentResult) moveCounts(name string) (int, int) {
+	cooperations, moves := 0, 0
+	for _, m := range r.Matchups {
+		if m.A == name {
+			cooperations += m.ACooperations
+			moves += m.Moves
+		}
+		if m.B == name {
+			cooperations += m.BCooperations
+			moves += m.Moves
+		}
+	}
+	return cooperations, moves
+}
+
+// AllDrawMatchups returns the matchups where every game was drawn
+func (r TournamentResult) AllDrawMatchups() []MatchupResult {
+	var draws []MatchupResult
+	for _, m := range r.Matchups {
+		if m.AllDraws() {
+			draws = append(draws, m)
+		}
+	}
+	return draws
+}
+
+// Bots returns the name of every bot that played in the tournament in order
+func (r TournamentResult) Bots() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, m := range r.Matchups {
+		for _, name := range []string{m.A, m.B} {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Standing totals up the matchups the named bot played as player A
+func (r TournamentResult) Standing(name string) Standing {
+	s := Standing{Ties: r.Ties}
+	for _, m := range r.Matchups {
+		if m.A != name {
+			continue
+		}
+		s.Games += m.Games
+		s.Wins += m.Wins
+		s.Losses += m.Losses
+		s.Draws += m.Draws
+		s.Score += m.AScore
+	}
+	return s
+}
+
+// GameRecord is what gets streamed to TournamentOptions.Records for every
+// game, the moves are written as a string of C and D
+type GameRecord struct {
+	A      string `json:"a"`
+	B      string `json:"b"`
+	Game   int    `json:"game"`
+	AScore int    `json:"a_score"`
+	BScore int    `json:"b_score"`
+	AMoves string `json:"a_moves"`
+	BMoves string `json:"b_moves"`
+
+	// Swapped is set when A actually sat as player B, see RandomizeSeating
+	Swapped bool `json:"swapped,omitempty"`
+}
+
+// recordWriter writes game records one per line, it is safe to share
+// between goroutines
+type recordWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+func newRecordWriter(w io.Writer) *recordWriter {
+	if w == nil {
+		return nil
+	}
+	return &recordWriter{enc: json.NewEncoder(w)}
+}
+
+func (w *recordWriter) write(record GameRecord) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = w.enc.Encode(record)
+	}
+}
+
+// RunTournament plays every bot against every bot, itself included, with a
+// copy made by cloneBot in the second seat when a bot plays itself. It
+// fails with ErrNoOpponents if there are no bots and stops at the first
+// invalid move a bot makes. Once ctx is done it stops between games and
+// returns the matchups finished so far along with ctx's error
+func RunTournament(ctx context.Context, bots map[string]Bot, opts TournamentOptions) (TournamentResult, error) {
+	if len(bots) == 0 {
+		return TournamentResult{}, ErrNoOpponents
+	}
+
+	names := sortedNames(bots)
+	var pairs []pairing
+	for _, k1 := range names {
+		for _, k2 := range names {
+			bBot := bots[k2]
+			if k1 == k2 {
+				bBot = cloneBot(bBot)
+			}
+			pairs = append(pairs, pairing{a: k1, b: k2, aBot: bots[k1], bBot: bBot})
+		}
+	}
+	return runPairings(ctx, pairs, opts)
+}
+
+// RunTournamentOf is RunTournament with every seat of every matchup given a
+// fresh bot from its constructor, so nothing a bot does in one matchup can
+// carry over into another
+func RunTournamentOf(ctx context.Context, constructors map[string]func() Bot, opts TournamentOptions) (TournamentResult, error) {
+	if len(constructors) == 0 {
+		return TournamentResult{}, ErrNoOpponents
+	}
+
+	names := make([]string, 0, len(constructors))
+	for name := range constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pairs []pairing
+	for _, k1 := range names {
+		for _, k2 := range names {
+			pairs = append(pairs, pairing{a: k1, b: k2, aBot: constructors[k1](), bBot: constructors[k2]()})
+		}
+	}
+	return runPairings(ctx, pairs, opts)
+}
+
+// CrossResult is a tournament where every row bot played every column bot
+// as player A, and the bots in each set never played each other
+type CrossResult struct {
+	TournamentResult
+	Rows    []string
+	Columns []string
+}
+
+// RunCrossTournament plays every bot in rows against every bot in columns,
+// for comparing a set of candidates against a set of benchmarks without
+// the candidates playing each other. A bot that is both a row and a column
+// plays a copy of itself, and it stops once ctx is done, like RunTournament
+func RunCrossTournament(ctx context.Context, rows, columns map[string]Bot, opts TournamentOptions) (CrossResult, error) {
+	if len(rows) == 0 || len(columns) == 0 {
+		return CrossResult{}, ErrNoOpponents
+	}
+
+	result := CrossResult{
+		Rows:    sortedNames(rows),
+		Columns: sortedNames(columns),
+	}
+	var pairs []pairing
+	for _, k1 := range result.Rows {
+		for _, k2 := range result.Columns {
+			bBot := columns[k2]
+			if sameInstance(rows[k1], bBot) {
+				bBot = cloneBot(bBot)
+			}
+			pairs = append(pairs, pairing{a: k1, b: k2, aBot: rows[k1], bBot: bBot})
+		}
+	}
+
+	var err error
+	result.TournamentResult, err = runPairings(ctx, pairs, opts)
+	return result, err
+}
+
+// Scores is the mean score per game of each row bot against each column bot
+func (r CrossResult) Scores() [][]float64 {
+	scores := make([][]float64, len(r.Rows))
+	for i := range scores {
+		scores[i] = make([]float64, len(r.Columns))
+	}
+
+	rows, columns := indexNames(r.Rows), indexNames(r.Columns)
+	for _, m := range r.Matchups {
+		if m.Games == 0 {
+			continue
+		}
+		scores[rows[m.A]][columns[m.B]] = float64(m.AScore) / float64(m.Games)
+	}
+	return scores
+}
+
+// sameInstance is true if a and b are pointers to the same bot, so playing
+// them against each other would have both seats sharing its state
+func sameInstance(a, b Bot) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	return va.Kind() == reflect.Ptr && vb.Kind() == reflect.Ptr && va.Pointer() == vb.Pointer()
+}
+
+// pairing is one matchup to be played in a tournament
+type pairing struct {
+	a, b       string
+	aBot, bBot Bot
+}
+
+func runPairings(ctx context.Context, pairs []pairing, opts TournamentOptions) (TournamentResult, error) {
+	games := opts.Games
+	if games <= 0 {
+		games = 100_000
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	records := newRecordWriter(opts.Records)
+
+	if opts.Seed != 0 {
+		shuffle := rand.New(rand.NewSource(opts.Seed))
+		shuffle.Shuffle(len(pairs), func(i, j int) {
+			pairs[i], pairs[j] = pairs[j], pairs[i]
+		})
+	}
+
+	// a bot can only be in one matchup at a time as most keep some state
+	// while they play, so each name gets a lock
+	locks := map[string]*sync.Mutex{}
+	for _, pair := range pairs {
+		locks[pair.a] = &sync.Mutex{}
+		locks[pair.b] = &sync.Mutex{}
+	}
+
+	result := TournamentResult{Ties: opts.Ties}
+	var start time.Time
+	if opts.Timing {
+		start = time.Now()
+	}
+
+	matchups := make([]MatchupResult, len(pairs))
+	errs := make([]error, len(pairs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				matchups[i], errs[i] = runPairing(ctx, pairs[i], games, opts, locks, records)
+			}
+		}()
+	}
+	for i := range pairs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if opts.Timing {
+		result.Elapsed = time.Since(start)
+	}
+	// keep every matchup that finished, which is all but the ones after an
+	// invalid move or cancellation
+	var firstErr error
+	for i, err := range errs {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		result.Matchups = append(result.Matchups, matchups[i])
+	}
+	if firstErr != nil {
+		return result, firstErr
+	}
+
+	if records != nil && records.err != nil {
+		return result, records.err
+	}
+	return result, nil
+}
+
+// runPairing plays one matchup once it has both of its bots to itself
+func runPairing(ctx context.Context, pair pairing, games int, opts TournamentOptions, locks map[string]*sync.Mutex, records *recordWriter) (MatchupResult, error) {
+	k1, k2 := pair.a, pair.b
+	first, second := locks[k1], locks[k2]
+	if k2 < k1 {
+		first, second = second, first
+	}
+	first.Lock()
+	defer first.Unlock()
+	if k1 != k2 {
+		second.Lock()
+		defer second.Unlock()
+	}
+
+	var matchupStart time.Time
+	if opts.Timing {
+		matchupStart = time.Now()
+	}
+	fixed := k1 == k2 && opts.Deterministic[k1]
+	// stopping early is only safe when neither bot is random
+	opts.EarlyStop = opts.EarlyStop && opts.Deterministic[k1] && opts.Deterministic[k2]
+	m, err := playMatchup(ctx, pair, games, fixed, opts, records)
+	if err != nil {
+		return m, err
+	}
+	if opts.Timing {
+		m.Elapsed = time.Since(matchupStart)
+	}
+	return m, nil
+}
+
+// gameSeed derives the seed one seat of one game of a matchup is played
+// with from the tournament's seed, so it doesn't matter what order or how
+// many at once the matchups are played in
+func gameSeed(seed uint64, a, b string, game int, seat byte) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], seed)
+	_, _ = h.Write(buf[:])
+	_, _ = io.WriteString(h, a)
+	_, _ = h.Write([]byte{0})
+	_, _ = io.WriteString(h, b)
+	_, _ = h.Write([]byte{0})
+	binary.LittleEndian.PutUint64(buf[:], uint64(game))
+	_, _ = h.Write(buf[:])
+	_, _ = h.Write([]byte{seat})
+	return h.Sum64()
+}
+
+func sortedNames(bots map[string]Bot) []string {
+	names := make([]string, 0, len(bots))
+	for name := range bots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func indexNames(names []string) map[string]int {
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	return index
+}
+
+// playMatchup plays the games of a pairing, if fixed every game is known to
+// be the same so only the first one is actually played. With a Seed bots
+// with their own random source are reseeded from it before every game
+func playMatchup(ctx context.Context, pair pairing, games int, fixed bool, opts TournamentOptions, records *recordWriter) (MatchupResult, error) {
+	k1, k2, b1, b2 := pair.a, pair.b, pair.aBot, pair.bBot
+	m := MatchupResult{A: k1, B: k2, Games: games}
+	meetBot(b1, k2)
+	meetBot(b2, k1)
+
+	var game Game
+	swapped := false
+	for i := 0; i < games; i++ {
+		if err := ctx.Err(); err != nil {
+			return m, err
+		}
+		if i == 0 || !fixed {
+			if opts.Seed != 0 {
+				reseedBot(b1, gameSeed(opts.Seed, k1, k2, i, 'a'))
+				reseedBot(b2, gameSeed(opts.Seed, k1, k2, i, 'b'))
+			}
+			swapped = opts.RandomizeSeating && swapSeats(opts.Seed, k1, k2, i)
+
+			var err error
+			if swapped {
+				game, _, err = playGame(b2, b1, opts.GameOptions, false)
+				game = game.Swap()
+			} else {
+				game, _, err = playGame(b1, b2, opts.GameOptions, false)
+			}
+			if err != nil {
+				return m, fmt.Errorf("%s against %s: %w", k1, k2, err)
+			}
+		}
+		m.add(game, opts.Ties)
+		if swapped {
+			m.Swapped++
+		}
+
+		records.write(GameRecord{
+			A:       k1,
+			B:       k2,
+			Game:    i,
+			AScore:  game.AScore,
+			BScore:  game.BScore,
+			AMoves:  movesString(game.AHistory),
+			BMoves:  movesString(game.BHistory),
+			Swapped: swapped,
+		})
+	}
+	return m, nil
+}
+
+// swapSeats decides whether a game is played with the bots in each other's
+// seats, from the tournament seed if there is one so it can be replayed
+func swapSeats(seed uint64, a, b string, game int) bool {
+	if seed == 0 {
+		return rand.Intn(2) == 1
+	}
+	// FNV's low bits barely change between games, its top bit does
+	return gameSeed(seed, a, b, game, 's')>>63 == 1
+}
+
+func movesString(moves []int) string {
+	b := make([]byte, len(moves))
+	for i, move := range moves {
+		b[i] = moveLetter(move)
+	}
+	return string(b)
+}
@@ -0,0 +1,772 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/exp/rand"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// selfPlayRecords plays bot against itself and returns the games it played
+func selfPlayRecords(t *testing.T, bot Bot, opts TournamentOptions) []GameRecord {
+	t.Helper()
+	records, _ := tournamentRecords(t, map[string]Bot{"bot": bot}, opts)
+	return records
+}
+
+// tournamentRecords runs a tournament and returns the games streamed from it
+func tournamentRecords(t *testing.T, bots map[string]Bot, opts TournamentOptions) ([]GameRecord, TournamentResult) {
+	t.Helper()
+	var buf bytes.Buffer
+	opts.Records = &buf
+	result, err := RunTournament(context.Background(), bots, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []GameRecord
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r GameRecord
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	return records, result
+}
+
+func TestSelfPlaySeparateSeats(t *testing.T) {
+	alternate := []int{Cooperate, Defect, Cooperate, Defect}
+	tests := []struct {
+		name string
+		bot  Bot
+		want string // moves each seat should play, if known
+	}{
+		{"ScriptedBot", &ScriptedBot{Moves: alternate, Loop: true}, "CDCDCDCDCD"},
+		{"ShubikBot", &ShubikBot{}, ""},
+		{"TidemanChieruzziBot", &TidemanChieruzziBot{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, r := range selfPlayRecords(t, tt.bot, TournamentOptions{Games: 3}) {
+				if r.AMoves != r.BMoves {
+					t.Errorf("game %d: seats played %s and %s", r.Game, r.AMoves, r.BMoves)
+				}
+				if tt.want != "" && r.AMoves != tt.want {
+					t.Errorf("game %d: played %s, want %s", r.Game, r.AMoves, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCrossTournamentSelfPlaySeparateSeats(t *testing.T) {
+	bots := map[string]Bot{"bot": &ScriptedBot{Moves: []int{Cooperate, Defect}, Loop: true}}
+	result, err := RunCrossTournament(context.Background(), bots, bots, TournamentOptions{Games: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// both seats cooperate in the same rounds
+	for round, c := range result.Matchups[0].Cooperations {
+		if want := 2 * (1 - round%2); c != want {
+			t.Errorf("round %d had %d cooperations, want %d", round+1, c, want)
+		}
+	}
+}
+
+func TestRecordsStreamed(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+	}{
+		{"one worker", 1},
+		{"several workers", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bots := map[string]Bot{
+				"DefectBot":    DefectBot{},
+				"RandomBot":    &RandomBot{},
+				"TitForTatBot": TitForTatBot{},
+			}
+			records, result := tournamentRecords(t, bots, TournamentOptions{Games: 7, Workers: tt.workers, Seed: 1})
+			if len(records) != result.Games() {
+				t.Fatalf("streamed %d records for %d games", len(records), result.Games())
+			}
+
+			games := map[[2]string]int{}
+			for _, r := range records {
+				games[[2]string{r.A, r.B}]++
+				if r.A != "DefectBot" || r.B != "TitForTatBot" {
+					continue
+				}
+				want := GameRecord{A: "DefectBot", B: "TitForTatBot", Game: r.Game, AScore: -6, BScore: -11,
+					AMoves: "DDDDDDDDDD", BMoves: "CDDDDDDDDD"}
+				if r != want {
+					t.Errorf("streamed %+v, want %+v", r, want)
+				}
+			}
+			for _, m := range result.Matchups {
+				if got := games[[2]string{m.A, m.B}]; got != m.Games {
+					t.Errorf("%s against %s: streamed %d games, played %d", m.A, m.B, got, m.Games)
+				}
+			}
+		})
+	}
+}
+
+func TestSeedShufflesMatchOrder(t *testing.T) {
+	// the order the matchups were played in, from the streamed records
+	order := func(seed uint64) []string {
+		bots := map[string]Bot{}
+		for _, name := range []string{"CooperateBot", "DefectBot", "MirrorBot", "ThresholdGrimBot", "TitForTatBot"} {
+			bots[name] = strategies[name]()
+		}
+		records, _ := tournamentRecords(t, bots, TournamentOptions{Games: 1, Workers: 1, Seed: seed})
+		var played []string
+		for _, r := range records {
+			played = append(played, r.A+" "+r.B)
+		}
+		return played
+	}
+
+	tests := []struct {
+		name  string
+		seeds [2]uint64
+		same  bool
+	}{
+		{"same seed", [2]uint64{8, 8}, true},
+		{"different seeds", [2]uint64{8, 9}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := order(tt.seeds[0]), order(tt.seeds[1])
+			if got := strings.Join(a, ",") == strings.Join(b, ","); got != tt.same {
+				t.Errorf("played in the same order = %v, want %v\n%v\n%v", got, tt.same, a, b)
+			}
+		})
+	}
+}
+
+func TestTimingCountsGames(t *testing.T) {
+	tests := []struct {
+		name string
+		opts TournamentOptions
+	}{
+		{"timed", TournamentOptions{Games: 7, Timing: true}},
+		{"timed in parallel", TournamentOptions{Games: 7, Timing: true, Workers: 3}},
+		{"self-play shortcut", TournamentOptions{Games: 7, Timing: true, Deterministic: map[string]bool{"TitForTatBot": true}}},
+		{"untimed", TournamentOptions{Games: 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bots := map[string]Bot{"RandomBot": &RandomBot{}, "TitForTatBot": TitForTatBot{}, "DefectBot": DefectBot{}}
+			result, err := RunTournament(context.Background(), bots, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// every bot against every other in both seats, and against itself
+			if want := 9 * tt.opts.Games; result.Games() != want {
+				t.Errorf("%d games reported, want %d", result.Games(), want)
+			}
+			for _, m := range result.Matchups {
+				if m.Games != tt.opts.Games {
+					t.Errorf("%s vs %s: %d games, want %d", m.A, m.B, m.Games, tt.opts.Games)
+				}
+			}
+			if timed := result.Elapsed > 0 && result.GamesPerSecond() > 0; timed != tt.opts.Timing {
+				t.Errorf("elapsed %v at %.0f games a second", result.Elapsed, result.GamesPerSecond())
+			}
+		})
+	}
+}
+
+func TestAllDrawMatchups(t *testing.T) {
+	tests := []struct {
+		name string
+		ties TiePolicy
+	}{
+		{"draw", TieDraw},
+		{"half win", TieHalfWin},
+		{"cooperation", TieCooperation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bots := map[string]Bot{"CooperateBot": CooperateBot{}, "DefectBot": DefectBot{}}
+			result, err := RunTournament(context.Background(), bots, TournamentOptions{Games: 5, Ties: tt.ties})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var flagged []string
+			for _, m := range result.AllDrawMatchups() {
+				flagged = append(flagged, m.A+" vs "+m.B)
+			}
+			sort.Strings(flagged)
+			if want := []string{"CooperateBot vs CooperateBot", "DefectBot vs DefectBot"}; !reflect.DeepEqual(flagged, want) {
+				t.Errorf("flagged %v, want %v", flagged, want)
+			}
+		})
+	}
+}
+
+func TestDeterministicSelfPlay(t *testing.T) {
+	deterministic := map[string]bool{
+		"TitForTatBot":        true,
+		"HardTitForTatBot":    true,
+		"TitForTatBotReverse": true,
+		"ShubikBot":           true,
+		"GradualBot":          true,
+		"cycle":               true,
+	}
+	tests := []struct {
+		name string
+		opts GameOptions
+	}{
+		{"default", GameOptions{}},
+		{"early stop", GameOptions{EarlyStop: true}},
+		{"long games", GameOptions{Rounds: 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bots := func() map[string]Bot {
+				return map[string]Bot{
+					"TitForTatBot":        TitForTatBot{},
+					"HardTitForTatBot":    HardTitForTatBot{},
+					"TitForTatBotReverse": TitForTatBotReverse{},
+					"ShubikBot":           &ShubikBot{},
+					"GradualBot":          &GradualBot{},
+					"cycle":               &ScriptedBot{Moves: moves("CCD"), Loop: true},
+					// not deterministic, so still played every time
+					"RandomBot": &RandomBot{},
+				}
+			}
+			opts := TournamentOptions{GameOptions: tt.opts, Games: 20, Seed: 7}
+			played, err := RunTournament(context.Background(), bots(), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts.Deterministic = deterministic
+			cached, err := RunTournament(context.Background(), bots(), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cached.Equal(played) {
+				t.Errorf("reusing self-play games gave\n%+v\nplaying them all gave\n%+v", cached.Matchups, played.Matchups)
+			}
+		})
+	}
+}
+
+func TestCooperationByRound(t *testing.T) {
+	cooperating := func(n int) []float64 {
+		fractions := make([]float64, n)
+		for i := range fractions {
+			fractions[i] = 1
+		}
+		return fractions
+	}
+	endgame := BackwardInductionBot{K: 2}
+	tests := []struct {
+		name string
+		bots map[string]Bot
+		want []float64
+	}{
+		{"tit for tat", map[string]Bot{"TitForTatBot": TitForTatBot{}}, cooperating(DefaultRounds)},
+		{"endgame defectors", map[string]Bot{"endgame": endgame}, append(cooperating(DefaultRounds-2), 0, 0)},
+		// tit for tat only answers the first endgame defection a round late
+		{"both", map[string]Bot{"TitForTatBot": TitForTatBot{}, "endgame": endgame}, append(cooperating(DefaultRounds-2), 0.5, 0.25)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RunTournament(context.Background(), tt.bots, TournamentOptions{Games: 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := result.CooperationByRound(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the same for a single matchup
+	result, err := RunTournament(context.Background(), map[string]Bot{"TitForTatBot": TitForTatBot{}, "endgame": endgame}, TournamentOptions{Games: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range result.Matchups {
+		if m.A == "TitForTatBot" && m.B == "endgame" {
+			want := append(cooperating(DefaultRounds-2), 0.5, 0)
+			if got := m.CooperationByRound(); !reflect.DeepEqual(got, want) {
+				t.Errorf("matchup got %v, want %v", got, want)
+			}
+		}
+	}
+}
+
+func TestCrossTournamentScores(t *testing.T) {
+	rows := map[string]Bot{"CooperateBot": CooperateBot{}, "DefectBot": DefectBot{}}
+	columns := map[string]Bot{
+		"TitForTatBot": TitForTatBot{},
+		"alternate":    &ScriptedBot{Moves: moves("CD"), Loop: true},
+	}
+	result, err := RunCrossTournament(context.Background(), rows, columns, TournamentOptions{Games: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Matchups) != 4 {
+		t.Errorf("%d matchups, want each row against each column", len(result.Matchups))
+	}
+	if want := []string{"CooperateBot", "DefectBot"}; !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("rows %v, want %v", result.Rows, want)
+	}
+	if want := []string{"TitForTatBot", "alternate"}; !reflect.DeepEqual(result.Columns, want) {
+		t.Errorf("columns %v, want %v", result.Columns, want)
+	}
+	want := [][]float64{
+		{10, 5 - 10},
+		{3 - 9, 15 - 5},
+	}
+	if got := result.Scores(); !reflect.DeepEqual(got, want) {
+		t.Errorf("scores %v, want %v", got, want)
+	}
+}
+
+func TestSaveTournamentResult(t *testing.T) {
+	run := func(seed uint64) TournamentResult {
+		bots := map[string]Bot{"RandomBot": &RandomBot{}, "TitForTatBot": TitForTatBot{}, "GrofmanBot": &GrofmanBot{}}
+		result, err := RunTournament(context.Background(), bots, TournamentOptions{Games: 30, Seed: seed, Timing: true, Workers: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	original := run(1)
+
+	path := filepath.Join(t.TempDir(), "result.json")
+	if err := original.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadTournamentResult(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Equal(original) {
+		t.Error("loaded result isn't equal to the one saved")
+	}
+	if loaded.Elapsed != original.Elapsed {
+		t.Errorf("elapsed time %v loaded as %v", original.Elapsed, loaded.Elapsed)
+	}
+
+	if other := run(2); other.Equal(loaded) {
+		t.Error("a tournament with another seed is equal to the saved one")
+	}
+	changed := loaded
+	changed.Matchups = append([]MatchupResult(nil), loaded.Matchups...)
+	changed.Matchups[0].Wins++
+	if changed.Equal(loaded) {
+		t.Error("an extra win went unnoticed")
+	}
+	if loaded.Equal(TournamentResult{Matchups: loaded.Matchups[1:]}) {
+		t.Error("a missing matchup went unnoticed")
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTournamentResult(path); err == nil {
+		t.Error("loaded a truncated result")
+	}
+}
+
+func TestWorkersReproducible(t *testing.T) {
+	run := func(workers int) TournamentResult {
+		bots := map[string]Bot{
+			"RandomBot":      &RandomBot{},
+			"GrofmanBot":     &GrofmanBot{},
+			"TullockBot":     &TullockBot{Margin: 0.1},
+			"RandomDefect":   &RandomDefectBot{Rate: 1.0 / 10},
+			"TitForTatBot":   TitForTatBot{},
+			"FixedMixedBot":  &FixedMixedBot{CoopProb: 0.5},
+			"RegretMatching": &RegretMatchingBot{},
+		}
+		result, err := RunTournament(context.Background(), bots, TournamentOptions{Games: 50, Seed: 21, Workers: workers, RandomizeSeating: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	serial := run(1)
+	for _, procs := range []int{1, 2, 8} {
+		t.Run(fmt.Sprintf("GOMAXPROCS %d", procs), func(t *testing.T) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+			for _, workers := range []int{2, 4, 16} {
+				if !run(workers).Equal(serial) {
+					t.Errorf("%d workers gave a different result to 1", workers)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomizeSeatingSplit(t *testing.T) {
+	const games = 20000
+	for _, seed := range []uint64{0, 5, 99} {
+		t.Run(fmt.Sprint("seed ", seed), func(t *testing.T) {
+			rand.Seed(7)
+			bots := map[string]Bot{"RandomBot": &RandomBot{}, "TitForTatBot": TitForTatBot{}}
+			result, err := RunTournament(context.Background(), bots, TournamentOptions{Games: games, Seed: seed, RandomizeSeating: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range result.Matchups {
+				if share := float64(m.Swapped) / games; math.Abs(share-0.5) > 0.015 {
+					t.Errorf("%s against %s swapped seats in %v of games, want about half", m.A, m.B, share)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomizeSeatingSymmetric(t *testing.T) {
+	// none of these care which seat they are in, so swapping can't change
+	// anything but the count of swaps
+	bots := func() map[string]Bot {
+		return map[string]Bot{
+			"CooperateBot":     CooperateBot{},
+			"DefectBot":        DefectBot{},
+			"TitForTatBot":     TitForTatBot{},
+			"ThresholdGrimBot": ThresholdGrimBot{K: 1},
+			"SlowTitForTatBot": SlowTitForTatBot{},
+		}
+	}
+	opts := TournamentOptions{Games: 30, Seed: 12, GameOptions: GameOptions{Rounds: 20}}
+	fixed, err := RunTournament(context.Background(), bots(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.RandomizeSeating = true
+	var records bytes.Buffer
+	opts.Records = &records
+	randomized, err := RunTournament(context.Background(), bots(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	swaps := 0
+	for i := range randomized.Matchups {
+		swaps += randomized.Matchups[i].Swapped
+		randomized.Matchups[i].Swapped = 0
+	}
+	if swaps == 0 {
+		t.Error("no game swapped seats")
+	}
+	if !randomized.Equal(fixed) {
+		t.Errorf("randomizing the seats gave\n%+v\nwant\n%+v", randomized.Matchups, fixed.Matchups)
+	}
+
+	// the records are from the matchup's point of view whoever sat where
+	dec := json.NewDecoder(&records)
+	for dec.More() {
+		var r GameRecord
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		if r.A == "DefectBot" && r.B == "CooperateBot" && (r.AMoves != strings.Repeat("D", 20) || r.AScore != 20*DefaultPayoff.Temptation) {
+			t.Errorf("DefectBot played %s and scored %d against CooperateBot, swapped %v", r.AMoves, r.AScore, r.Swapped)
+		}
+	}
+}
+
+// cancellingBot cooperates and cancels its context once it has made After
+// decisions
+type cancellingBot struct {
+	After  int
+	cancel context.CancelFunc
+	moves  int
+}
+
+func (r *cancellingBot) Decision(state GameState) int {
+	r.moves++
+	if r.moves == r.After {
+		r.cancel()
+	}
+	return Cooperate
+}
+
+func TestTournamentCancel(t *testing.T) {
+	const games = 10
+	tests := []struct {
+		name     string
+		after    int // decisions before it cancels, 0 cancels before the start
+		matchups int // that finish, played in the order A-A, A-Z, Z-A, Z-Z
+	}{
+		{"before the start", 0, 0},
+		{"part way through the second matchup", 3, 1},
+		{"part way through the third matchup", games*DefaultRounds + 3, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.after == 0 {
+				cancel()
+			}
+			bots := map[string]Bot{"A": TitForTatBot{}, "Z": &cancellingBot{After: tt.after, cancel: cancel}}
+			result, err := RunTournament(ctx, bots, TournamentOptions{Games: games})
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("got %v, want context.Canceled", err)
+			}
+			if len(result.Matchups) != tt.matchups {
+				t.Errorf("kept %d matchups, want %d", len(result.Matchups), tt.matchups)
+			}
+			for _, m := range result.Matchups {
+				if m.Games != games {
+					t.Errorf("kept %s against %s with %d games, want all %d", m.A, m.B, m.Games, games)
+				}
+			}
+		})
+	}
+}
+
+func TestMoveEntropy(t *testing.T) {
+	bots := map[string]Bot{
+		"CooperateBot": CooperateBot{},
+		"DefectBot":    DefectBot{},
+		"RandomBot":    &RandomBot{},
+		"alternate":    &ScriptedBot{Moves: moves("CD"), Loop: true},
+	}
+	result, err := RunTournament(context.Background(), bots, TournamentOptions{Games: 2000, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// alternating from the start of every game is C five times in ten
+	p := 5.0 / 10
+	alternate := -p*math.Log2(p) - (1-p)*math.Log2(1-p)
+	tests := []struct {
+		name      string
+		want      float64
+		tolerance float64
+	}{
+		{"CooperateBot", 0, 0},
+		{"DefectBot", 0, 0},
+		{"RandomBot", 1, 0.001},
+		{"alternate", alternate, 1e-9},
+		{"missing", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := result.MoveEntropy(tt.name); math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("MoveEntropy = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTiePolicy(t *testing.T) {
+	// every game ties, they cooperate throughout
+	tied := map[string]Bot{"CooperateBot": CooperateBot{}, "TitForTatBot": TitForTatBot{}}
+	tests := []struct {
+		name            string
+		ties            TiePolicy
+		win, loss, draw float64
+	}{
+		{"draw", TieDraw, 0, 0, 100},
+		{"half win", TieHalfWin, 50, 50, 0},
+		// they cooperated as often as each other so it stays a draw
+		{"cooperation", TieCooperation, 0, 0, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RunTournament(context.Background(), tied, TournamentOptions{Games: 10, Ties: tt.ties})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"CooperateBot", "TitForTatBot"} {
+				s := result.Standing(name)
+				if s.WinRate() != tt.win || s.LossRate() != tt.loss || s.DrawRate() != tt.draw {
+					t.Errorf("%s won %v%%, lost %v%% and drew %v%%, want %v%%, %v%% and %v%%",
+						name, s.WinRate(), s.LossRate(), s.DrawRate(), tt.win, tt.loss, tt.draw)
+				}
+			}
+		})
+	}
+}
+
+func TestTieCooperation(t *testing.T) {
+	// holding both scores at zero ties every game, leaving only how much
+	// each cooperated to tell them apart
+	bots := map[string]Bot{"CooperateBot": CooperateBot{}, "DefectBot": DefectBot{}}
+	opts := TournamentOptions{Games: 10, GameOptions: GameOptions{ScoreBounds: &ScoreBounds{}}}
+	tests := []struct {
+		ties         TiePolicy
+		wins, losses int // CooperateBot's against DefectBot, the rest drawn
+	}{
+		{TieDraw, 0, 0},
+		{TieHalfWin, 0, 0},
+		{TieCooperation, 10, 0},
+	}
+	for _, tt := range tests {
+		opts.Ties = tt.ties
+		result, err := RunTournament(context.Background(), bots, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range result.Matchups {
+			wins, losses := tt.wins, tt.losses
+			switch {
+			case m.A == "DefectBot" && m.B == "CooperateBot":
+				wins, losses = losses, wins
+			case m.A == m.B:
+				// playing itself it cooperates exactly as much
+				wins, losses = 0, 0
+			}
+			if m.Wins != wins || m.Losses != losses || m.Draws != m.Games-wins-losses {
+				t.Errorf("policy %d: %s against %s won %d, lost %d and drew %d, want %d, %d and %d",
+					tt.ties, m.A, m.B, m.Wins, m.Losses, m.Draws, wins, losses, m.Games-wins-losses)
+			}
+		}
+	}
+}
+
+func TestMergeTournamentResults(t *testing.T) {
+	bots := func() map[string]Bot {
+		return map[string]Bot{
+			"GrofmanBot":   &GrofmanBot{},
+			"RandomBot":    &RandomBot{},
+			"TitForTatBot": TitForTatBot{},
+			"DefectBot":    DefectBot{},
+		}
+	}
+	opts := TournamentOptions{Games: 30, Seed: 17, RandomizeSeating: true}
+	whole, err := RunTournament(context.Background(), bots(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("halves", func(t *testing.T) {
+		// each half plays the rows it was given against every bot, the
+		// seeds games are played with don't depend on which half they are in
+		all := bots()
+		first := map[string]Bot{"GrofmanBot": all["GrofmanBot"], "RandomBot": all["RandomBot"]}
+		second := map[string]Bot{"TitForTatBot": all["TitForTatBot"], "DefectBot": all["DefectBot"]}
+		var parts []TournamentResult
+		for _, rows := range []map[string]Bot{first, second} {
+			part, err := RunCrossTournament(context.Background(), rows, bots(), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parts = append(parts, part.TournamentResult)
+		}
+
+		merged, err := MergeTournamentResults(parts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !merged.Equal(whole) {
+			t.Errorf("merged\n%+v\nwant\n%+v", merged.Matchups, whole.Matchups)
+		}
+	})
+
+	t.Run("games of the same matchups", func(t *testing.T) {
+		deterministic := func() map[string]Bot {
+			return map[string]Bot{"TitForTatBot": TitForTatBot{}, "DefectBot": DefectBot{}, "cycle": &ScriptedBot{Moves: moves("CCD"), Loop: true}}
+		}
+		whole, err := RunTournament(context.Background(), deterministic(), TournamentOptions{Games: 20})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var parts []TournamentResult
+		for _, games := range []int{5, 15} {
+			part, err := RunTournament(context.Background(), deterministic(), TournamentOptions{Games: games})
+			if err != nil {
+				t.Fatal(err)
+			}
+			parts = append(parts, part)
+		}
+		merged, err := MergeTournamentResults(parts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !merged.Equal(whole) {
+			t.Errorf("merged\n%+v\nwant\n%+v", merged.Matchups, whole.Matchups)
+		}
+		// merging doesn't touch the parts
+		if parts[0].Games() != 5*9 {
+			t.Errorf("first part now has %d games, want %d", parts[0].Games(), 5*9)
+		}
+		for _, m := range parts[0].Matchups {
+			if m.Cooperations[0] > 2*5 {
+				t.Errorf("first part's %s against %s cooperated %d times in the first round of 5 games", m.A, m.B, m.Cooperations[0])
+			}
+		}
+	})
+
+	t.Run("different tie policies", func(t *testing.T) {
+		halfWin := whole
+		halfWin.Ties = TieHalfWin
+		if _, err := MergeTournamentResults(whole, halfWin); err == nil {
+			t.Error("merged parts that counted ties differently")
+		}
+	})
+}
+
+func TestSeedLeavesGlobalSourceAlone(t *testing.T) {
+	rand.Seed(5)
+	want := rand.Uint64()
+
+	rand.Seed(5)
+	bots := map[string]Bot{"RandomBot": &RandomBot{}, "TitForTatBot": TitForTatBot{}}
+	if _, err := RunTournament(context.Background(), bots, TournamentOptions{Games: 10, Seed: 99}); err != nil {
+		t.Fatal(err)
+	}
+	if got := rand.Uint64(); got != want {
+		t.Errorf("global source drew %d after the tournament, want %d", got, want)
+	}
+}
+
+func TestSeedReplaysTournament(t *testing.T) {
+	tests := []struct {
+		name  string
+		seeds [2]uint64
+		equal bool
+	}{
+		{"same seed", [2]uint64{3, 3}, true},
+		{"different seeds", [2]uint64{3, 4}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var results [2]TournamentResult
+			for i, seed := range tt.seeds {
+				bots := map[string]Bot{
+					"RandomBot":       &RandomBot{},
+					"RandomDefectBot": &RandomDefectBot{Rate: 1.0 / 10},
+					"TitForTatBot":    TitForTatBot{},
+				}
+				var err error
+				results[i], err = RunTournament(context.Background(), bots, TournamentOptions{Games: 20, Seed: seed})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := results[0].Equal(results[1]); got != tt.equal {
+				t.Errorf("Equal = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+// Traits are the four properties Axelrod found the successful strategies in
+// his tournaments shared
+type Traits struct {
+	Nice        bool // never the first to defect
+	Retaliatory bool // answers an unprovoked defection with one of its own
+	Forgiving   bool // goes back to cooperating once the opponent does
+	Clear       bool // plays the same way every time and settles into a pattern
+}
+
+// traitRounds is the fewest rounds a game is played over to read the traits,
+// long enough for a bot to answer the probe and then settle down
+const traitRounds = 20
+
+// retaliationWindow is how many rounds a bot has to answer a defection for it
+// to count as retaliating, so tit for two tats still counts
+const retaliationWindow = 3
+
+// Characterize labels the bot with Traits by playing it against opponents
+// built to bring each of them out: unconditional cooperators and defectors,
+// tit for tat, an opponent that defects once halfway through and then goes
+// back to cooperating, and one cycling C, C, D. Every game is played twice
+// with the bot reseeded between them, so a bot that moves at random isn't
+// clear
+func Characterize(b Bot, opts GameOptions) Traits {
+	if opts.Rounds < traitRounds {
+		opts.Rounds = traitRounds
+	}
+	opts.EarlyStop = false
+
+	betrayal := opts.Rounds / 2
+	betrayer := make([]int, betrayal+2)
+	betrayer[betrayal] = Defect
+
+	opponents := map[string]func() Bot{
+		"cooperator": func() Bot { return CooperateBot{} },
+		"defector":   func() Bot { return DefectBot{} },
+		"titfortat":  func() Bot { return TitForTatBot{} },
+		"betrayer":   func() Bot { return &ScriptedBot{Moves: betrayer} },
+		"cycle":      func() Bot { return &ScriptedBot{Moves: []int{Cooperate, Cooperate, Defect}, Loop: true} },
+	}
+
+	traits := Traits{Nice: true, Clear: true}
+	for name, opponent := range opponents {
+		reseedBot(b, 1)
+		game := PlayGame(opponent(), b, opts)
+		reseedBot(b, 2)
+		again := PlayGame(opponent(), b, opts)
+
+		moves, opponentMoves := game.BHistory, game.AHistory
+		if !sameMoves(moves, again.BHistory) {
+			traits.Clear = false
+		}
+		if first := firstDefection(moves); first < len(moves) && first <= firstDefection(opponentMoves) {
+			traits.Nice = false
+		}
+
+		switch name {
+		case "betrayer":
+			answer := moves[betrayal+1:]
+			if len(answer) > retaliationWindow {
+				answer = answer[:retaliationWindow]
+			}
+			traits.Retaliatory = countMoves(answer, Defect) > 0
+			traits.Forgiving = countMoves(moves[betrayal+1:], Cooperate) > 0
+		case "cycle":
+			if !periodic(moves, 3) {
+				traits.Clear = false
+			}
+		}
+	}
+	return traits
+}
+
+// firstDefection is the round of the first defection in moves, or the
+// length of moves if there wasn't one
+func firstDefection(moves []int) int {
+	for i, move := range moves {
+		if move == Defect {
+			return i
+		}
+	}
+	return len(moves)
+}
+
+func sameMoves(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// periodic is true if the second half of moves repeats every period rounds
+func periodic(moves []int, period int) bool {
+	for i := len(moves)/2 + period; i < len(moves); i++ {
+		if moves[i] != moves[i-period] {
+			return false
+		}
+	}
+	return true
+}
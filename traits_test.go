@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestCharacterize(t *testing.T) {
+	tests := []struct {
+		name string
+		bot  Bot
+		want Traits
+	}{
+		{"TitForTatBot", TitForTatBot{}, Traits{Nice: true, Retaliatory: true, Forgiving: true, Clear: true}},
+		// it defects whatever the betrayer does, which counts as answering
+		{"DefectBot", DefectBot{}, Traits{Nice: false, Retaliatory: true, Forgiving: false, Clear: true}},
+		{"CooperateBot", CooperateBot{}, Traits{Nice: true, Retaliatory: false, Forgiving: true, Clear: true}},
+		{"ThresholdGrimBot", ThresholdGrimBot{K: 1}, Traits{Nice: true, Retaliatory: true, Forgiving: false, Clear: true}},
+		{"ForgivingGrudgerBot", ForgivingGrudgerBot{Punish: 2}, Traits{Nice: true, Retaliatory: true, Forgiving: true, Clear: true}},
+		// the probe is the first defection of the game, and the betrayer
+		// cooperated through it so gets exploited for good
+		{"ProberBot", NewProberBot(), Traits{Nice: false, Retaliatory: true, Forgiving: false, Clear: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Characterize(tt.bot, GameOptions{}); got != tt.want {
+				t.Errorf("Characterize = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// playing differently once reseeded isn't clear
+	if got := Characterize(&RandomBot{}, GameOptions{}); got.Clear {
+		t.Errorf("RandomBot is clear: %+v", got)
+	}
+}
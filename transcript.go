@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Transcript is the moves of a game someone wrote down, player A's and
+// player B's for every round
+type Transcript struct {
+	A []int
+	B []int
+}
+
+// ReadTranscript reads a transcript with a line for every round holding A's
+// move and then B's, as C or D (or cooperate or defect) split by spaces or
+// a comma. Blank lines and lines starting with # are skipped
+func ReadTranscript(r io.Reader) (Transcript, error) {
+	var t Transcript
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(fields) != 2 {
+			return t, fmt.Errorf("line %d: expected two moves, got %q", line, text)
+		}
+		a, aOK := parseMove(fields[0])
+		b, bOK := parseMove(fields[1])
+		if !aOK || !bOK {
+			return t, fmt.Errorf("line %d: %w: %q", line, ErrInvalidMove, text)
+		}
+		t.A = append(t.A, a)
+		t.B = append(t.B, b)
+	}
+	if err := scanner.Err(); err != nil {
+		return t, fmt.Errorf("reading transcript: %w", err)
+	}
+	return t, nil
+}
+
+// Check rebuilds the game in the transcript round by round and asks b for
+// its move each round as player A, or as player B with asB, given what was
+// actually played so far. It returns the game and the first round (from 0)
+// where b would have played something other than what was written down, or
+// -1 if b would have played the whole transcript. The game lasts as long as
+// the transcript unless opts says otherwise
+func (t Transcript) Check(b Bot, asB bool, opts GameOptions) (Game, int) {
+	if opts.Rounds <= 0 {
+		opts.Rounds = len(t.A)
+	}
+	game := NewGame(opts)
+	resetBot(b)
+
+	_ = game.Play(gameDecision{
+		aChoice: NoMove,
+		bChoice: NoMove,
+	})
+
+	mismatch := -1
+	for i := range t.A {
+		if game.GameOver() {
+			break
+		}
+
+		state, recorded := game.State().Swap(), t.A[i]
+		if asB {
+			state, recorded = game.State(), t.B[i]
+		}
+		if b.Decision(state) != recorded && mismatch == -1 {
+			mismatch = i
+		}
+
+		_ = game.Play(gameDecision{
+			aChoice: t.A[i],
+			bChoice: t.B[i],
+		})
+	}
+
+	game.AwardCooperationBonus()
+	return game, mismatch
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadTranscript(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		a, b  string
+		err   bool
+	}{
+		{"spaces", "C C\nD C\nC D\n", "CDC", "CCD", false},
+		{"commas and words", "cooperate,defect\nD, C\n", "CD", "DC", false},
+		{"comments and blank lines", "# A B\n\nC D\n  \nD D\n", "CD", "DD", false},
+		{"one move", "C\n", "", "", true},
+		{"three moves", "C D C\n", "", "", true},
+		{"not a move", "C X\n", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcript, err := ReadTranscript(strings.NewReader(tt.input))
+			if tt.err {
+				if err == nil {
+					t.Error("read without an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a, b := movesString(transcript.A), movesString(transcript.B); a != tt.a || b != tt.b {
+				t.Errorf("read %s and %s, want %s and %s", a, b, tt.a, tt.b)
+			}
+		})
+	}
+
+	if _, err := ReadTranscript(strings.NewReader("C Q")); !errors.Is(err, ErrInvalidMove) {
+		t.Errorf("got %v, want ErrInvalidMove", err)
+	}
+}
+
+func TestTranscriptCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		bot      Bot
+		asB      bool
+		mismatch int
+	}{
+		{"tit for tat as B", "CDCDD", "CCDCD", TitForTatBot{}, true, -1},
+		// tit for tat would have answered the defection in the second round
+		{"not tit for tat as B", "CDCDD", "CCCCD", TitForTatBot{}, true, 2},
+		{"tit for tat as A", "CCDC", "CDCC", TitForTatBot{}, false, -1},
+		{"always defect as A", "CCDC", "CDCC", DefectBot{}, false, 0},
+		// only the first mismatch is reported
+		{"several mismatches", "DDDD", "CCCC", CooperateBot{}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcript := Transcript{A: moves(tt.a), B: moves(tt.b)}
+			game, mismatch := transcript.Check(tt.bot, tt.asB, GameOptions{})
+			if mismatch != tt.mismatch {
+				t.Errorf("first mismatch in round %d, want %d", mismatch, tt.mismatch)
+			}
+			// the game is what was written down whatever the bot would have
+			// played
+			if movesString(game.AHistory) != tt.a || movesString(game.BHistory) != tt.b {
+				t.Errorf("rebuilt %s and %s, want %s and %s",
+					movesString(game.AHistory), movesString(game.BHistory), tt.a, tt.b)
+			}
+		})
+	}
+
+	transcript := Transcript{A: moves("CDCDD"), B: moves("CCDCD")}
+	game, _ := transcript.Check(TitForTatBot{}, true, GameOptions{})
+	if game.AScore != 4 || game.BScore != -1 {
+		t.Errorf("scored %d to %d, want 4 to -1", game.AScore, game.BScore)
+	}
+}